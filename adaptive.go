@@ -0,0 +1,91 @@
+package dhook
+
+import (
+	"sync"
+	"time"
+)
+
+// AdjustableRateLimiter represents a [RateLimiter] whose limit can be changed while in use.
+//
+// The adaptive webhook rate limit enabled by [WithAdaptiveWebhookRateLimit]
+// requires webhook rate limiters to implement this interface.
+// The default rate limiter does.
+type AdjustableRateLimiter interface {
+	RateLimiter
+
+	// SetLimit changes the maximum number of requests per period.
+	SetLimit(requests int)
+}
+
+// adaptiveRate learns the effective rate limit of a webhook.
+//
+// It lowers the rate after an unexpected 429 response
+// and slowly probes back up by one request after every quiet period without 429 responses.
+// This type is safe for concurrent use by multiple goroutines.
+type adaptiveRate struct {
+	limiter AdjustableRateLimiter
+	logger  Logger
+	max     int
+	min     int
+	period  time.Duration
+
+	mu         sync.Mutex
+	current    int
+	lastChange time.Time
+}
+
+// newAdaptiveRate returns a new adaptiveRate for a limiter, which starts with the rate requests per period.
+func newAdaptiveRate(limiter AdjustableRateLimiter, requests, min, max int, period time.Duration, logger Logger, now time.Time) *adaptiveRate {
+	a := &adaptiveRate{
+		current:    requests,
+		lastChange: now,
+		limiter:    limiter,
+		logger:     logger,
+		max:        max,
+		min:        min,
+		period:     period,
+	}
+	a.current = a.clamp(requests)
+	if a.current != requests {
+		a.limiter.SetLimit(a.current)
+	}
+	return a
+}
+
+// decrease lowers the rate after an unexpected 429 response by a quarter, but at least by one.
+func (a *adaptiveRate) decrease(now time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.lastChange = now
+	x := a.clamp(min(a.current*3/4, a.current-1))
+	if x == a.current {
+		return
+	}
+	a.current = x
+	a.limiter.SetLimit(x)
+	a.logger.Warn("Webhook rate limit lowered", "requests", x, "period", a.period)
+}
+
+// probe raises the rate by one, when a quiet period has passed since the last change.
+func (a *adaptiveRate) probe(now time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if now.Sub(a.lastChange) < a.period || a.current >= a.max {
+		return
+	}
+	a.lastChange = now
+	a.current++
+	a.limiter.SetLimit(a.current)
+	a.logger.Info("Webhook rate limit raised", "requests", a.current, "period", a.period)
+}
+
+// limit returns the current rate.
+func (a *adaptiveRate) limit() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.current
+}
+
+func (a *adaptiveRate) clamp(x int) int {
+	return min(max(x, a.min), a.max)
+}
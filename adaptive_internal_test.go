@@ -0,0 +1,62 @@
+package dhook
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdaptiveRate(t *testing.T) {
+	newRate := func(requests, min, max int) (*adaptiveRate, *limiter) {
+		l := newLimiter(requests, time.Minute, "", slog.Default(), realClock{})
+		return newAdaptiveRate(l, requests, min, max, time.Minute, slog.Default(), time.Now()), l
+	}
+	t.Run("should start with given rate", func(t *testing.T) {
+		a, l := newRate(30, 1, 50)
+		assert.Equal(t, 30, a.limit())
+		assert.Equal(t, 30, l.State().Limit)
+	})
+	t.Run("should clamp start rate", func(t *testing.T) {
+		a, l := newRate(30, 1, 20)
+		assert.Equal(t, 20, a.limit())
+		assert.Equal(t, 20, l.State().Limit)
+	})
+	t.Run("should lower rate by a quarter", func(t *testing.T) {
+		a, l := newRate(30, 1, 50)
+		a.decrease(time.Now())
+		assert.Equal(t, 22, a.limit())
+		assert.Equal(t, 22, l.State().Limit)
+	})
+	t.Run("should lower small rate by at least one", func(t *testing.T) {
+		a, _ := newRate(3, 1, 50)
+		a.decrease(time.Now())
+		assert.Equal(t, 2, a.limit())
+	})
+	t.Run("should not lower rate below min", func(t *testing.T) {
+		a, _ := newRate(30, 25, 50)
+		a.decrease(time.Now())
+		assert.Equal(t, 25, a.limit())
+	})
+	t.Run("should raise rate by one after quiet period", func(t *testing.T) {
+		a, l := newRate(30, 1, 50)
+		now := time.Now()
+		a.decrease(now)
+		a.probe(now.Add(time.Minute))
+		assert.Equal(t, 23, a.limit())
+		assert.Equal(t, 23, l.State().Limit)
+	})
+	t.Run("should not raise rate before quiet period has passed", func(t *testing.T) {
+		a, _ := newRate(30, 1, 50)
+		now := time.Now()
+		a.decrease(now)
+		a.probe(now.Add(59 * time.Second))
+		assert.Equal(t, 22, a.limit())
+	})
+	t.Run("should not raise rate above max", func(t *testing.T) {
+		a, _ := newRate(30, 1, 30)
+		a.probe(time.Now().Add(time.Hour))
+		assert.Equal(t, 30, a.limit())
+	})
+}
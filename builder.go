@@ -0,0 +1,217 @@
+package dhook
+
+import (
+	"slices"
+	"time"
+)
+
+// MessageBuilder builds a [Message] step by step.
+//
+// Example:
+//
+//	m, err := dhook.NewMessage().
+//		Content("Hello").
+//		Embed(func(e *dhook.EmbedBuilder) {
+//			e.Title("Status").Field("CPU", "42%", true).Color(dhook.ColorRed)
+//		}).
+//		Build()
+type MessageBuilder struct {
+	counting Counting
+	m        Message
+	roles    []string // IDs of roles mentioned with the builder
+	users    []string // IDs of users mentioned with the builder
+}
+
+// NewMessage returns a new builder for a message.
+func NewMessage() *MessageBuilder {
+	return &MessageBuilder{}
+}
+
+// AllowedMentions sets the allowed mentions.
+// Otherwise only the users and roles mentioned with [MessageBuilder.MentionUser]
+// and [MessageBuilder.MentionRole] are allowed.
+func (b *MessageBuilder) AllowedMentions(am AllowedMentions) *MessageBuilder {
+	b.m.AllowedMentions = &am
+	return b
+}
+
+// MentionRole returns a mention of a role, which is allowed for the message.
+//
+// Example:
+//
+//	b := dhook.NewMessage()
+//	m, err := b.Content("Alert for " + b.MentionRole("123")).Build()
+func (b *MessageBuilder) MentionRole(id string) string {
+	if !slices.Contains(b.roles, id) {
+		b.roles = append(b.roles, id)
+	}
+	return RoleMention(id)
+}
+
+// MentionUser returns a mention of a user, which is allowed for the message.
+func (b *MessageBuilder) MentionUser(id string) string {
+	if !slices.Contains(b.users, id) {
+		b.users = append(b.users, id)
+	}
+	return UserMention(id)
+}
+
+// AvatarURL sets the URL of the avatar, which overrides the default avatar of the webhook.
+func (b *MessageBuilder) AvatarURL(url string) *MessageBuilder {
+	b.m.AvatarURL = url
+	return b
+}
+
+// Counting sets how characters are counted for overflowing fields and for validation.
+// The default is [CountCodePoints].
+func (b *MessageBuilder) Counting(c Counting) *MessageBuilder {
+	b.counting = c
+	return b
+}
+
+// Content sets the content of the message.
+func (b *MessageBuilder) Content(s string) *MessageBuilder {
+	b.m.Content = s
+	return b
+}
+
+// Username sets the username, which overrides the default username of the webhook.
+func (b *MessageBuilder) Username(s string) *MessageBuilder {
+	b.m.Username = s
+	return b
+}
+
+// Embed adds an embed, which is built by fn.
+// When fields have overflowed into continuation embeds with [EmbedBuilder.AddFieldOrOverflow],
+// all of them are added.
+func (b *MessageBuilder) Embed(fn func(e *EmbedBuilder)) *MessageBuilder {
+	e := &EmbedBuilder{counting: b.counting, embeds: []Embed{{}}}
+	fn(e)
+	b.m.Embeds = append(b.m.Embeds, e.embeds...)
+	return b
+}
+
+// Build returns the message.
+// It returns a [ValidationError] when the message fails validation
+// with characters counted as set with [MessageBuilder.Counting].
+// An invalid message is returned as well, e.g. to distribute it across several messages with [EmbedPacker].
+func (b *MessageBuilder) Build() (Message, error) {
+	m := b.m
+	if m.AllowedMentions == nil {
+		m.AllowedMentions = &AllowedMentions{
+			Roles: slices.Clone(b.roles),
+			Users: slices.Clone(b.users),
+		}
+	}
+	return m, Validator{Counting: b.counting}.Validate(m)
+}
+
+// MustBuild is like [MessageBuilder.Build], but panics when the message is invalid.
+func (b *MessageBuilder) MustBuild() Message {
+	m, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// EmbedBuilder builds an [Embed] step by step. See [MessageBuilder.Embed].
+//
+// Once fields have overflowed into a continuation embed with [EmbedBuilder.AddFieldOrOverflow],
+// all methods apply to the continuation embed.
+type EmbedBuilder struct {
+	counting Counting
+	embeds   []Embed // the last embed is the current one
+}
+
+// current returns the embed being built.
+func (b *EmbedBuilder) current() *Embed {
+	return &b.embeds[len(b.embeds)-1]
+}
+
+// Author sets the author.
+func (b *EmbedBuilder) Author(name, url, iconURL string) *EmbedBuilder {
+	b.current().Author = Author{Name: name, URL: url, IconURL: iconURL}
+	return b
+}
+
+// Color sets the color.
+func (b *EmbedBuilder) Color(c Color) *EmbedBuilder {
+	b.current().Color = c
+	return b
+}
+
+// Description sets the description.
+func (b *EmbedBuilder) Description(s string) *EmbedBuilder {
+	b.current().Description = s
+	return b
+}
+
+// Field adds a field.
+func (b *EmbedBuilder) Field(name, value string, inline bool) *EmbedBuilder {
+	em := b.current()
+	em.Fields = append(em.Fields, Field{Name: name, Value: value, Inline: inline})
+	return b
+}
+
+// AddFieldOrOverflow adds a field. When the field would exceed the number of fields
+// or the size limit of the embed, it starts a continuation embed with the same color
+// and a "(cont.)" title and adds the field there.
+// The footer and timestamp are moved to the continuation embed, so that they stay last.
+//
+// All embeds of a message share the combined size limit,
+// which is not resolved by overflowing. Use [EmbedPacker] to distribute such a message across several messages.
+func (b *EmbedBuilder) AddFieldOrOverflow(name, value string, inline bool) *EmbedBuilder {
+	f := Field{Name: name, Value: value, Inline: inline}
+	em := b.current()
+	if len(em.Fields) > 0 && !b.counting.CanAddField(*em, f) {
+		next := Embed{
+			Color:     em.Color,
+			Footer:    em.Footer,
+			Timestamp: em.Timestamp,
+			Title:     continuedTitle(b.embeds[0].Title, b.counting),
+		}
+		em.Footer = Footer{}
+		em.Timestamp = time.Time{}
+		b.embeds = append(b.embeds, next)
+		em = b.current()
+	}
+	em.Fields = append(em.Fields, f)
+	return b
+}
+
+// Footer sets the footer.
+func (b *EmbedBuilder) Footer(text, iconURL string) *EmbedBuilder {
+	b.current().Footer = Footer{Text: text, IconURL: iconURL}
+	return b
+}
+
+// Image sets the URL of the image.
+func (b *EmbedBuilder) Image(url string) *EmbedBuilder {
+	b.current().Image = Image{URL: url}
+	return b
+}
+
+// Thumbnail sets the URL of the thumbnail.
+func (b *EmbedBuilder) Thumbnail(url string) *EmbedBuilder {
+	b.current().Thumbnail = Image{URL: url}
+	return b
+}
+
+// Timestamp sets the timestamp.
+func (b *EmbedBuilder) Timestamp(t time.Time) *EmbedBuilder {
+	b.current().Timestamp = t
+	return b
+}
+
+// Title sets the title.
+func (b *EmbedBuilder) Title(s string) *EmbedBuilder {
+	b.current().Title = s
+	return b
+}
+
+// URL sets the URL of the title.
+func (b *EmbedBuilder) URL(url string) *EmbedBuilder {
+	b.current().URL = url
+	return b
+}
@@ -0,0 +1,184 @@
+package dhook_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ErikKalkoken/go-dhook"
+)
+
+func TestMessageBuilder(t *testing.T) {
+	t.Run("should build message", func(t *testing.T) {
+		ts := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+		got, err := dhook.NewMessage().
+			Content("content").
+			Username("username").
+			AvatarURL("https://www.example.com/avatar.png").
+			Embed(func(e *dhook.EmbedBuilder) {
+				e.Title("title").
+					Description("description").
+					URL("https://www.example.com").
+					Color(dhook.ColorRed).
+					Author("author", "https://www.example.com/author", "https://www.example.com/author.png").
+					Field("alpha", "1", true).
+					Field("bravo", "2", false).
+					Footer("footer", "https://www.example.com/footer.png").
+					Image("https://www.example.com/image.png").
+					Thumbnail("https://www.example.com/thumbnail.png").
+					Timestamp(ts)
+			}).
+			Build()
+		if assert.NoError(t, err) {
+			want := dhook.Message{
+				AllowedMentions: &dhook.AllowedMentions{},
+				AvatarURL:       "https://www.example.com/avatar.png",
+				Content:         "content",
+				Embeds: []dhook.Embed{{
+					Author: dhook.Author{
+						Name:    "author",
+						URL:     "https://www.example.com/author",
+						IconURL: "https://www.example.com/author.png",
+					},
+					Color:       dhook.ColorRed,
+					Description: "description",
+					Fields: []dhook.Field{
+						{Name: "alpha", Value: "1", Inline: true},
+						{Name: "bravo", Value: "2"},
+					},
+					Footer:    dhook.Footer{Text: "footer", IconURL: "https://www.example.com/footer.png"},
+					Image:     dhook.Image{URL: "https://www.example.com/image.png"},
+					Thumbnail: dhook.Image{URL: "https://www.example.com/thumbnail.png"},
+					Timestamp: ts,
+					Title:     "title",
+					URL:       "https://www.example.com",
+				}},
+				Username: "username",
+			}
+			assert.Equal(t, want, got)
+		}
+	})
+	t.Run("should report all violations", func(t *testing.T) {
+		_, err := dhook.NewMessage().
+			Content(makeStr(2001)).
+			Embed(func(e *dhook.EmbedBuilder) {
+				e.Title(makeStr(257)).Field("", "value", false)
+			}).
+			Build()
+		var errValidation dhook.ValidationError
+		if assert.ErrorAs(t, err, &errValidation) {
+			var paths []string
+			for _, x := range errValidation.Errors {
+				paths = append(paths, x.Path)
+			}
+			assert.Equal(t, []string{"content", "embeds[0].title", "embeds[0].fields[0].name"}, paths)
+		}
+		assert.ErrorIs(t, err, dhook.ErrInvalidMessage)
+	})
+	t.Run("should report empty message", func(t *testing.T) {
+		_, err := dhook.NewMessage().Build()
+		assert.ErrorIs(t, err, dhook.ErrInvalidMessage)
+	})
+	t.Run("should panic on invalid message", func(t *testing.T) {
+		assert.Panics(t, func() {
+			dhook.NewMessage().MustBuild()
+		})
+	})
+	t.Run("should return message", func(t *testing.T) {
+		got := dhook.NewMessage().Content("content").MustBuild()
+		assert.Equal(t, dhook.Message{AllowedMentions: &dhook.AllowedMentions{}, Content: "content"}, got)
+	})
+	t.Run("should allow only mentions created with the builder", func(t *testing.T) {
+		b := dhook.NewMessage()
+		got := b.Content(
+			"Hi " + b.MentionUser("1") + " " + b.MentionUser("1") + " and " + b.MentionRole("2") +
+				" and " + dhook.UserMention("3") + " and @everyone",
+		).MustBuild()
+		assert.Equal(t, "Hi <@1> <@1> and <@&2> and <@3> and @everyone", got.Content)
+		assert.Equal(t, &dhook.AllowedMentions{Users: []string{"1"}, Roles: []string{"2"}}, got.AllowedMentions)
+	})
+	t.Run("should keep explicit allowed mentions", func(t *testing.T) {
+		am := dhook.AllowedMentions{Parse: []dhook.MentionType{dhook.MentionEveryone}}
+		got := dhook.NewMessage().Content("@everyone " + dhook.UserMention("1")).AllowedMentions(am).MustBuild()
+		assert.Equal(t, &am, got.AllowedMentions)
+	})
+}
+
+func TestEmbedBuilder_AddFieldOrOverflow(t *testing.T) {
+	t.Run("should add fields when there is room", func(t *testing.T) {
+		got := dhook.NewMessage().Embed(func(e *dhook.EmbedBuilder) {
+			e.AddFieldOrOverflow("alpha", "1", false).AddFieldOrOverflow("bravo", "2", false)
+		}).MustBuild()
+		if assert.Len(t, got.Embeds, 1) {
+			assert.Len(t, got.Embeds[0].Fields, 2)
+		}
+	})
+	t.Run("should overflow into continuation embed when there are too many fields", func(t *testing.T) {
+		ts := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+		got := dhook.NewMessage().Embed(func(e *dhook.EmbedBuilder) {
+			e.Title("title").Color(dhook.ColorRed).Footer("footer", "").Timestamp(ts)
+			for i := range 30 {
+				e.AddFieldOrOverflow(fmt.Sprint(i), "value", false)
+			}
+		}).MustBuild()
+		if assert.Len(t, got.Embeds, 2) {
+			first, second := got.Embeds[0], got.Embeds[1]
+			assert.Equal(t, "title", first.Title)
+			assert.Len(t, first.Fields, 25)
+			assert.Empty(t, first.Footer)
+			assert.Zero(t, first.Timestamp)
+			assert.Equal(t, "title (cont.)", second.Title)
+			assert.Equal(t, dhook.ColorRed, second.Color)
+			assert.Len(t, second.Fields, 5)
+			assert.Equal(t, "25", second.Fields[0].Name)
+			assert.Equal(t, "footer", second.Footer.Text)
+			assert.Equal(t, ts, second.Timestamp)
+		}
+	})
+	t.Run("should overflow into continuation embed when embed is too large", func(t *testing.T) {
+		m, err := dhook.NewMessage().Embed(func(e *dhook.EmbedBuilder) {
+			for i := range 7 {
+				e.AddFieldOrOverflow(fmt.Sprint(i), makeStr(1000), false)
+			}
+		}).Build()
+		assert.ErrorIs(t, err, dhook.ErrInvalidMessage) // combined size limit is still exceeded
+		if assert.Len(t, m.Embeds, 2) {
+			assert.Len(t, m.Embeds[0].Fields, 5)
+			assert.Len(t, m.Embeds[1].Fields, 2)
+			assert.Equal(t, "(cont.)", m.Embeds[1].Title)
+		}
+		for _, x := range (dhook.EmbedPacker{}).Pack(m) {
+			assert.NoError(t, x.Validate())
+		}
+	})
+	t.Run("should overflow with characters counted as set", func(t *testing.T) {
+		m, err := dhook.NewMessage().Counting(dhook.CountUTF16).Embed(func(e *dhook.EmbedBuilder) {
+			for i := range 7 {
+				e.AddFieldOrOverflow(fmt.Sprint(i), strings.Repeat("😀", 500), false)
+			}
+		}).Build()
+		assert.ErrorIs(t, err, dhook.ErrInvalidMessage) // combined size limit is exceeded in UTF-16
+		if assert.Len(t, m.Embeds, 2) {
+			assert.Len(t, m.Embeds[0].Fields, 5)
+			assert.Len(t, m.Embeds[1].Fields, 2)
+		}
+		for _, x := range (dhook.EmbedPacker{Counting: dhook.CountUTF16}).Pack(m) {
+			assert.NoError(t, dhook.Validator{Counting: dhook.CountUTF16}.Validate(x))
+		}
+	})
+	t.Run("should apply to continuation embed after overflow", func(t *testing.T) {
+		got := dhook.NewMessage().Embed(func(e *dhook.EmbedBuilder) {
+			for i := range 26 {
+				e.AddFieldOrOverflow(fmt.Sprint(i), "value", false)
+			}
+			e.Description("description")
+		}).MustBuild()
+		if assert.Len(t, got.Embeds, 2) {
+			assert.Empty(t, got.Embeds[0].Description)
+			assert.Equal(t, "description", got.Embeds[1].Description)
+		}
+	})
+}
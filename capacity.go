@@ -0,0 +1,80 @@
+package dhook
+
+// Capacity represents the remaining room of a message for each of Discord's limits.
+// Negative values mean that a limit is exceeded.
+type Capacity struct {
+	Content        int   // Remaining characters for the content
+	Embeds         int   // Remaining number of embeds
+	EmbedsCombined int   // Remaining characters for all embeds combined
+	Fields         []int // Remaining number of fields for each embed
+}
+
+// Size returns the number of characters of a message, i.e. its content and the combined size of its embeds.
+// Characters are counted as code points. See also [Counting.Size].
+func (m Message) Size() int {
+	return CountCodePoints.Size(m)
+}
+
+// Size returns the number of characters of a message counted by c.
+func (c Counting) Size(m Message) int {
+	return c.Len(m.Content) + m.embedsSize(c)
+}
+
+// embedsSize returns the combined size of all embeds with characters counted by c.
+func (m Message) embedsSize(c Counting) int {
+	var n int
+	for _, em := range m.Embeds {
+		n += em.size(c)
+	}
+	return n
+}
+
+// Capacity returns the remaining room of a message for each of Discord's limits.
+// This allows building messages incrementally, e.g. for digests.
+// Characters are counted as code points. See also [Counting.Capacity].
+func (m Message) Capacity() Capacity {
+	return CountCodePoints.Capacity(m)
+}
+
+// Capacity returns the remaining room of a message with characters counted by c.
+func (c Counting) Capacity(m Message) Capacity {
+	x := Capacity{
+		Content:        contentLength - c.Len(m.Content),
+		Embeds:         embedsQuantity - len(m.Embeds),
+		EmbedsCombined: embedCombinedLength - m.embedsSize(c),
+		Fields:         make([]int, len(m.Embeds)),
+	}
+	for i, em := range m.Embeds {
+		x.Fields[i] = fieldsQuantity - len(em.Fields)
+	}
+	return x
+}
+
+// CanAddEmbed reports whether an embed can be added to a message
+// without exceeding the number of embeds or their combined size.
+// The embed itself is not validated.
+// Characters are counted as code points. See also [Counting.CanAddEmbed].
+func (m Message) CanAddEmbed(em Embed) bool {
+	return CountCodePoints.CanAddEmbed(m, em)
+}
+
+// CanAddEmbed reports whether an embed can be added to a message with characters counted by c.
+func (c Counting) CanAddEmbed(m Message, em Embed) bool {
+	return len(m.Embeds) < embedsQuantity && m.embedsSize(c)+em.size(c) <= embedCombinedLength
+}
+
+// CanAddField reports whether a field can be added to an embed
+// without exceeding the number of fields or the combined size limit for embeds.
+// The field itself is not validated.
+// Characters are counted as code points. See also [Counting.CanAddField].
+//
+// The combined size limit applies to all embeds of a message,
+// so a message with several embeds might have less room. See also [Message.Capacity].
+func (em Embed) CanAddField(f Field) bool {
+	return CountCodePoints.CanAddField(em, f)
+}
+
+// CanAddField reports whether a field can be added to an embed with characters counted by c.
+func (c Counting) CanAddField(em Embed, f Field) bool {
+	return len(em.Fields) < fieldsQuantity && em.size(c)+f.size(c) <= embedCombinedLength
+}
@@ -0,0 +1,118 @@
+package dhook_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ErikKalkoken/go-dhook"
+)
+
+func TestMessage_Size(t *testing.T) {
+	m := dhook.Message{
+		Content: "alpha",
+		Embeds: []dhook.Embed{{
+			Title:  "bravo",
+			Fields: []dhook.Field{{Name: "charlie", Value: "delta"}},
+		}},
+	}
+	assert.Equal(t, 12, m.Embeds[0].Fields[0].Size())
+	assert.Equal(t, 17, m.Embeds[0].Size())
+	assert.Equal(t, 22, m.Size())
+}
+
+func TestMessage_Capacity(t *testing.T) {
+	m := dhook.Message{
+		Content: "alpha",
+		Embeds: []dhook.Embed{
+			{Title: "bravo", Fields: []dhook.Field{{Name: "charlie", Value: "delta"}}},
+			{Description: makeStr(6000)},
+		},
+	}
+	got := m.Capacity()
+	assert.Equal(t, dhook.Capacity{
+		Content:        1995,
+		Embeds:         8,
+		EmbedsCombined: -17,
+		Fields:         []int{24, 25},
+	}, got)
+}
+
+func TestMessage_CanAddEmbed(t *testing.T) {
+	cases := []struct {
+		name string
+		m    dhook.Message
+		em   dhook.Embed
+		want bool
+	}{
+		{"empty message", dhook.Message{}, dhook.Embed{Title: "title"}, true},
+		{
+			"fits exactly",
+			dhook.Message{Embeds: []dhook.Embed{{Description: makeStr(4000)}}},
+			dhook.Embed{Description: makeStr(2000)},
+			true,
+		},
+		{
+			"too large",
+			dhook.Message{Embeds: []dhook.Embed{{Description: makeStr(4000)}}},
+			dhook.Embed{Description: makeStr(2001)},
+			false,
+		},
+		{"too many", dhook.Message{Embeds: make([]dhook.Embed, 10)}, dhook.Embed{Title: "title"}, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.m.CanAddEmbed(tc.em))
+		})
+	}
+}
+
+func TestEmbed_CanAddField(t *testing.T) {
+	cases := []struct {
+		name string
+		em   dhook.Embed
+		f    dhook.Field
+		want bool
+	}{
+		{"empty embed", dhook.Embed{}, dhook.Field{Name: "name", Value: "value"}, true},
+		{"too large", dhook.Embed{Description: makeStr(5995)}, dhook.Field{Name: "name", Value: "value"}, false},
+		{"too many", dhook.Embed{Fields: make([]dhook.Field, 25)}, dhook.Field{Name: "name", Value: "value"}, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.em.CanAddField(tc.f))
+		})
+	}
+}
+
+func TestCounting_Capacity(t *testing.T) {
+	m := dhook.Message{
+		Content: "😀",
+		Embeds:  []dhook.Embed{{Title: "😀", Fields: []dhook.Field{{Name: "😀", Value: "😀"}}}},
+	}
+	t.Run("should report size", func(t *testing.T) {
+		assert.Equal(t, 4, dhook.CountCodePoints.Size(m))
+		assert.Equal(t, 8, dhook.CountUTF16.Size(m))
+	})
+	t.Run("should report capacity", func(t *testing.T) {
+		assert.Equal(t, dhook.Capacity{
+			Content:        1998,
+			Embeds:         9,
+			EmbedsCombined: 5994,
+			Fields:         []int{24},
+		}, dhook.CountUTF16.Capacity(m))
+	})
+	t.Run("should report whether embed can be added", func(t *testing.T) {
+		em := dhook.Embed{Description: strings.Repeat("😀", 2998)}
+		assert.True(t, m.CanAddEmbed(em))
+		assert.False(t, dhook.CountUTF16.CanAddEmbed(m, em))
+		assert.True(t, dhook.CountUTF16.CanAddEmbed(m, dhook.Embed{Description: strings.Repeat("😀", 2997)}))
+	})
+	t.Run("should report whether field can be added", func(t *testing.T) {
+		em := dhook.Embed{Description: strings.Repeat("😀", 2998)}
+		f := dhook.Field{Name: "name", Value: "value"}
+		assert.True(t, em.CanAddField(f))
+		assert.False(t, dhook.CountUTF16.CanAddField(em, f))
+	})
+}
@@ -1,15 +1,22 @@
 package dhook
 
 import (
+	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
 	"time"
+	"weak"
 )
 
 const (
 	globalRateLimitPeriodDefault    = 1 * time.Second
 	globalRateLimitRequestsDefault  = 50
 	httpTimeoutDefault              = 30 * time.Second
+	userAgentDefault                = "DiscordBot (https://github.com/ErikKalkoken/go-dhook, " + Version + ")"
 	webhookRateLimitPeriodDefault   = 60 * time.Second
 	webhookRateLimitRequestsDefault = 30
 )
@@ -26,15 +33,34 @@ type (
 	// Client represents a shared client used by all webhooks to access the Discord API.
 	// This enables sharing the HTTP client and the global rate limit among all webhooks.
 	Client struct {
+		adaptiveMax              int
+		adaptiveMin              int
+		clock                    Clock
+		counting                 Counting
+		events                   *eventHub
+		globalLimiterFactory     RateLimiterFactory
 		globalRateLimitPeriod    time.Duration
 		globalRateLimitRequests  int
+		headers                  http.Header
+		hooks                    Hooks
 		httpClient               *http.Client
 		httpTimeout              time.Duration
-		limiterGlobal            *limiter
+		limiterGlobal            RateLimiter
+		queueGlobal              *fairQueue
 		logger                   Logger
+		pacing                   Pacing
+		proxyURL                 *url.URL
+		redactBodies             bool
 		rl                       rateLimited
+		stats                    *stats
+		userAgent                string
+		validation               ValidationPolicy
+		webhookLimiterFactory    RateLimiterFactory
 		webhookRateLimitPeriod   time.Duration
 		webhookRateLimitRequests int
+
+		mu       sync.Mutex
+		webhooks []weak.Pointer[Webhook] // webhooks for status and metrics, which are forgotten when no longer in use
 	}
 
 	// ClientOption represents an option for configuring a [Client].
@@ -101,6 +127,162 @@ func WithWebhookRateLimit(requests int, period time.Duration) ClientOption {
 	}
 }
 
+// WithAdaptiveWebhookRateLimit enables the adaptive webhook rate limit for a client.
+//
+// In adaptive mode webhooks learn their effective rate limit:
+// After an unexpected 429 response a webhook lowers its rate and
+// after every quiet period without 429 responses it slowly probes back up.
+// The rate starts with the webhook rate limit, see [WithWebhookRateLimit],
+// and is always kept between minRequests and maxRequests per period.
+// The learned rate is reported by [Webhook.RateLimit] and logged whenever it changes.
+//
+// Adaptive mode requires webhook rate limiters to implement [AdjustableRateLimiter].
+func WithAdaptiveWebhookRateLimit(minRequests, maxRequests int) ClientOption {
+	if minRequests <= 0 {
+		panic("invalid min requests")
+	}
+	if maxRequests < minRequests {
+		panic("invalid max requests")
+	}
+	return func(s *Client) {
+		s.adaptiveMin = minRequests
+		s.adaptiveMax = maxRequests
+	}
+}
+
+// WithClock sets a custom clock for a client.
+// The clock is used by all rate limits, except for custom rate limiters.
+// This allows testing time-based behavior deterministically, e.g. with a fake clock.
+func WithClock(clock Clock) ClientOption {
+	if clock == nil {
+		panic("must provide a clock")
+	}
+	return func(s *Client) {
+		s.clock = clock
+	}
+}
+
+// WithDefaultPacing sets how requests are spread within the webhook rate limit for all webhooks of a client.
+// The default is [PacingBurst]. It can be overridden for a webhook with [WithPacing].
+//
+// Pacing requires the default webhook rate limiter and is ignored for custom rate limiters.
+func WithDefaultPacing(pacing Pacing) ClientOption {
+	return func(s *Client) {
+		s.pacing = pacing
+	}
+}
+
+// WithGlobalRateLimiter sets a custom factory for creating the global rate limiter of a client.
+// The factory is called once with the configured global rate limit, see also [WithGlobalRateLimit].
+func WithGlobalRateLimiter(factory RateLimiterFactory) ClientOption {
+	if factory == nil {
+		panic("must provide a factory")
+	}
+	return func(s *Client) {
+		s.globalLimiterFactory = factory
+	}
+}
+
+// WithWebhookRateLimiter sets a custom factory for creating the rate limiters of webhooks.
+// The factory is called for every new webhook with the configured webhook rate limit,
+// see also [WithWebhookRateLimit].
+func WithWebhookRateLimiter(factory RateLimiterFactory) ClientOption {
+	if factory == nil {
+		panic("must provide a factory")
+	}
+	return func(s *Client) {
+		s.webhookLimiterFactory = factory
+	}
+}
+
+// WithValidationPolicy sets how a client validates messages before sending them.
+//
+// Invalid messages are rejected or fixed before they use up a rate limit slot.
+// This saves capacity, because a 400 Bad Request response from Discord counts towards the rate limits.
+func WithValidationPolicy(policy ValidationPolicy) ClientOption {
+	if policy > ValidationFix {
+		panic("invalid validation policy")
+	}
+	return func(s *Client) {
+		s.validation = policy
+	}
+}
+
+// WithCounting sets how a client counts characters when validating, truncating and splitting messages.
+// Use the same counting for [EmbedPacker] and [MessageBuilder.Counting], so that their messages pass validation.
+// The default is [CountCodePoints].
+func WithCounting(counting Counting) ClientOption {
+	if counting > CountUTF16 {
+		panic("invalid counting")
+	}
+	return func(s *Client) {
+		s.counting = counting
+	}
+}
+
+// WithUserAgent sets a custom User-Agent header for all requests of a client.
+//
+// Discord requires the form "DiscordBot ($url, $version)".
+// The default is "DiscordBot (https://github.com/ErikKalkoken/go-dhook, $version)",
+// where $version is the version of this library.
+func WithUserAgent(userAgent string) ClientOption {
+	if userAgent == "" {
+		panic("must provide a user agent")
+	}
+	return func(s *Client) {
+		s.userAgent = userAgent
+	}
+}
+
+// WithHeader adds a static header to all requests of a client.
+// It can be used multiple times, also for adding several values to the same header.
+func WithHeader(key, value string) ClientOption {
+	if key == "" {
+		panic("must provide a header key")
+	}
+	return func(s *Client) {
+		s.headers.Add(key, value)
+	}
+}
+
+// WithRedactedBodies redacts the bodies of messages and responses in all log output of a client.
+// Webhook tokens are always redacted.
+func WithRedactedBodies() ClientOption {
+	return func(s *Client) {
+		s.redactBodies = true
+	}
+}
+
+// WithHooks sets callbacks for observing the requests of a client, e.g. for tracing.
+// See [Hooks] for details.
+func WithHooks(hooks Hooks) ClientOption {
+	return func(s *Client) {
+		s.hooks = hooks
+	}
+}
+
+// WithRateLimitProxy configures a client to send all requests through a rate limit proxy,
+// e.g. dhook-proxy, instead of directly to Discord.
+//
+// The scheme and host of every webhook URL are replaced with the ones from baseURL
+// and the path of baseURL is prepended to the webhook path.
+// This allows several processes to share the same rate limits.
+//
+// Since the proxy may hold requests until a rate limit slot becomes free,
+// the HTTP timeout of the client should be set accordingly, e.g. with [WithHTTPTimeout].
+func WithRateLimitProxy(baseURL string) ClientOption {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		panic(fmt.Sprintf("invalid proxy URL: %s", err))
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		panic("invalid proxy URL: " + baseURL)
+	}
+	return func(s *Client) {
+		s.proxyURL = u
+	}
+}
+
 // NewClient returns a new [Client] with defaults.
 // The default client uses [http.DefaultClient] as HTTP client,
 // a HTTP timeout of 30 seconds and [slog.Default] as logger.
@@ -109,41 +291,155 @@ func WithWebhookRateLimit(requests int, period time.Duration) ClientOption {
 // for example with [WithHTTPClient].
 func NewClient(opts ...ClientOption) *Client {
 	client := &Client{
+		clock:                    realClock{},
 		globalRateLimitPeriod:    globalRateLimitPeriodDefault,
 		globalRateLimitRequests:  globalRateLimitRequestsDefault,
 		httpClient:               http.DefaultClient,
 		httpTimeout:              httpTimeoutDefault,
+		headers:                  make(http.Header),
 		logger:                   slog.Default(),
+		userAgent:                userAgentDefault,
 		webhookRateLimitPeriod:   webhookRateLimitPeriodDefault,
 		webhookRateLimitRequests: webhookRateLimitRequestsDefault,
 	}
 	for _, opt := range opts {
 		opt(client)
 	}
-	client.limiterGlobal = newLimiter(
+	if client.globalLimiterFactory == nil {
+		client.globalLimiterFactory = func(_ string, requests int, period time.Duration) RateLimiter {
+			return newLimiter(requests, period, "global", client.logger, client.clock)
+		}
+	}
+	if client.webhookLimiterFactory == nil {
+		client.webhookLimiterFactory = func(_ string, requests int, period time.Duration) RateLimiter {
+			return newLimiter(requests, period, "webhook", client.logger, client.clock)
+		}
+	}
+	client.limiterGlobal = client.globalLimiterFactory(
+		"",
 		client.globalRateLimitRequests,
 		client.globalRateLimitPeriod,
-		"global",
-		client.logger,
 	)
+	client.queueGlobal = newFairQueue(client.limiterGlobal)
+	client.stats = newStats()
+	client.events = newEventHub()
 	return client
 }
 
 // NewWebhook returns a new webhook for a client.
-func (c *Client) NewWebhook(url string) *Webhook {
+//
+// The webhook can be optionally configured through options,
+// for example with [WithWeight].
+func (c *Client) NewWebhook(url string, opts ...WebhookOption) *Webhook {
 	if c.limiterGlobal == nil {
 		panic("can not use uninitialized Client")
 	}
+	id := webhookID(url)
 	wh := &Webhook{
 		client: c,
-		url:    url,
-		limiterWebhook: newLimiter(
+		id:     id,
+		url:    c.requestURL(url),
+		limiterWebhook: c.webhookLimiterFactory(
+			id,
 			c.webhookRateLimitRequests,
 			c.webhookRateLimitPeriod,
-			"webhook",
-			c.logger,
 		),
+		pacing: c.pacing,
+		weight: 1,
 	}
+	wh.limiterAPI.clock = c.clock
 	wh.limiterAPI.logger = c.logger
+	for _, opt := range opts {
+		opt(wh)
+	}
+	if l, ok := wh.limiterWebhook.(*limiter); ok {
+		l.setPacing(wh.pacing)
+	} else if wh.pacing.Mode != PacingBurst {
+		c.logger.Warn("Pacing not supported by rate limiter")
+	}
+	if c.adaptiveMax > 0 {
+		if l, ok := wh.limiterWebhook.(AdjustableRateLimiter); ok {
+			wh.adaptive = newAdaptiveRate(
+				l,
+				c.webhookRateLimitRequests,
+				c.adaptiveMin,
+				c.adaptiveMax,
+				c.webhookRateLimitPeriod,
+				c.logger,
+				c.clock.Now(),
+			)
+		} else {
+			c.logger.Warn("Adaptive webhook rate limit not supported by rate limiter")
+		}
+	}
+	c.mu.Lock()
+	c.pruneWebhooks()
+	c.webhooks = append(c.webhooks, weak.Make(wh))
+	c.mu.Unlock()
 	return wh
 }
+
+// webhookList returns all webhooks of a client, which are still in use, ordered by their IDs.
+// Webhooks with the same ID are returned in the order of their creation.
+func (c *Client) webhookList() []*Webhook {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pruneWebhooks()
+	s := make([]*Webhook, 0, len(c.webhooks))
+	for _, p := range c.webhooks {
+		if wh := p.Value(); wh != nil {
+			s = append(s, wh)
+		}
+	}
+	slices.SortStableFunc(s, func(a, b *Webhook) int {
+		return strings.Compare(a.id, b.id)
+	})
+	return s
+}
+
+// pruneWebhooks removes webhooks, which are no longer in use. Caller must hold the lock.
+func (c *Client) pruneWebhooks() {
+	c.webhooks = slices.DeleteFunc(c.webhooks, func(p weak.Pointer[Webhook]) bool {
+		return p.Value() == nil
+	})
+}
+
+// requestURL returns the URL for sending requests to a webhook.
+// This is the original URL, unless the client has been configured to use a rate limit proxy.
+func (c *Client) requestURL(rawURL string) string {
+	if c.proxyURL == nil {
+		return rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	u.Scheme = c.proxyURL.Scheme
+	u.Host = c.proxyURL.Host
+	u.Path = strings.TrimSuffix(c.proxyURL.Path, "/") + u.Path
+	u.RawPath = ""
+	return u.String()
+}
+
+// EstimateDelay returns how long sending a message would have to wait right now
+// to comply with the global rate limit.
+// See also [Webhook.EstimateDelay] for an estimate which includes all rate limits of a webhook.
+func (c *Client) EstimateDelay() time.Duration {
+	if c.queueGlobal == nil {
+		return 0
+	}
+	now := c.clock.Now()
+	d := c.queueGlobal.delay(now)
+	if isActive, retryAfter := c.rl.getOrReset(now); isActive {
+		d = max(d, retryAfter)
+	}
+	return d
+}
+
+// loggedBody returns a message or response body for logging, which is redacted when configured.
+func (c *Client) loggedBody(s string) string {
+	if c.redactBodies {
+		return redacted
+	}
+	return s
+}
@@ -3,10 +3,13 @@ package dhook
 import (
 	"log/slog"
 	"net/http"
+	"runtime"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/ErikKalkoken/go-dhook/dhooktest"
 )
 
 type MyLogger struct{}
@@ -24,6 +27,7 @@ func TestClient(t *testing.T) {
 		assert.Equal(t, slog.Default(), c.logger)
 		assert.Equal(t, globalRateLimitPeriodDefault, c.globalRateLimitPeriod)
 		assert.Equal(t, globalRateLimitRequestsDefault, c.globalRateLimitRequests)
+		assert.Equal(t, "DiscordBot (https://github.com/ErikKalkoken/go-dhook, "+Version+")", c.userAgent)
 	})
 	t.Run("custom HTTP timeout", func(t *testing.T) {
 		c := NewClient(WithHTTPTimeout(1 * time.Second))
@@ -49,4 +53,141 @@ func TestClient(t *testing.T) {
 		assert.Equal(t, 10*time.Second, c.webhookRateLimitPeriod)
 		assert.Equal(t, 100, c.webhookRateLimitRequests)
 	})
+	t.Run("custom global rate limiter", func(t *testing.T) {
+		l := newLimiter(1, time.Second, "", &MyLogger{}, realClock{})
+		var gotID string
+		var gotRequests int
+		var gotPeriod time.Duration
+		c := NewClient(
+			WithGlobalRateLimit(7, 3*time.Second),
+			WithGlobalRateLimiter(func(webhookID string, requests int, period time.Duration) RateLimiter {
+				gotID, gotRequests, gotPeriod = webhookID, requests, period
+				return l
+			}))
+		assert.Same(t, l, c.limiterGlobal)
+		assert.Equal(t, "", gotID)
+		assert.Equal(t, 7, gotRequests)
+		assert.Equal(t, 3*time.Second, gotPeriod)
+	})
+	t.Run("custom webhook rate limiter", func(t *testing.T) {
+		l := newLimiter(1, time.Second, "", &MyLogger{}, realClock{})
+		var gotID string
+		var gotRequests int
+		var gotPeriod time.Duration
+		c := NewClient(
+			WithWebhookRateLimit(7, 3*time.Second),
+			WithWebhookRateLimiter(func(webhookID string, requests int, period time.Duration) RateLimiter {
+				gotID, gotRequests, gotPeriod = webhookID, requests, period
+				return l
+			}))
+		wh := c.NewWebhook("https://discord.com/api/webhooks/123/token")
+		assert.Same(t, l, wh.limiterWebhook)
+		assert.Equal(t, "123", gotID)
+		assert.Equal(t, 7, gotRequests)
+		assert.Equal(t, 3*time.Second, gotPeriod)
+	})
+	t.Run("custom webhook weight", func(t *testing.T) {
+		c := NewClient()
+		assert.Equal(t, 1, c.NewWebhook("url").weight)
+		assert.Equal(t, 3, c.NewWebhook("url", WithWeight(3)).weight)
+	})
+	t.Run("adaptive webhook rate limit", func(t *testing.T) {
+		c := NewClient(WithAdaptiveWebhookRateLimit(5, 40))
+		wh := c.NewWebhook("url")
+		assert.NotNil(t, wh.adaptive)
+	})
+	t.Run("adaptive webhook rate limit not supported by rate limiter", func(t *testing.T) {
+		c := NewClient(
+			WithLogger(&MyLogger{}),
+			WithAdaptiveWebhookRateLimit(5, 40),
+			WithWebhookRateLimiter(func(_ string, _ int, _ time.Duration) RateLimiter {
+				return &tickLimiter{}
+			}))
+		wh := c.NewWebhook("url")
+		assert.Nil(t, wh.adaptive)
+	})
+	t.Run("default pacing", func(t *testing.T) {
+		c := NewClient(WithDefaultPacing(Pacing{Mode: PacingSmooth}))
+		wh := c.NewWebhook("url")
+		assert.Equal(t, PacingSmooth, wh.limiterWebhook.(*limiter).pacer.pacing.Mode)
+	})
+	t.Run("webhook pacing", func(t *testing.T) {
+		c := NewClient(WithDefaultPacing(Pacing{Mode: PacingSmooth}))
+		wh := c.NewWebhook("url", WithPacing(Pacing{Mode: PacingBurstThenSmooth, Burst: 5}))
+		assert.Equal(t, Pacing{Mode: PacingBurstThenSmooth, Burst: 5}, wh.limiterWebhook.(*limiter).pacer.pacing)
+	})
+	t.Run("custom clock", func(t *testing.T) {
+		clock := dhooktest.NewFakeClock(time.Now())
+		c := NewClient(WithClock(clock))
+		assert.Equal(t, clock, c.clock)
+		wh := c.NewWebhook("url")
+		assert.Equal(t, clock, wh.limiterAPI.clock)
+		assert.Equal(t, clock, wh.limiterWebhook.(*limiter).clock)
+	})
+	t.Run("custom rate limit proxy", func(t *testing.T) {
+		c := NewClient(WithRateLimitProxy("http://localhost:8080"))
+		assert.Equal(t, "http://localhost:8080", c.proxyURL.String())
+	})
+	t.Run("custom user agent", func(t *testing.T) {
+		c := NewClient(WithUserAgent("DiscordBot (https://www.example.com, 1.0)"))
+		assert.Equal(t, "DiscordBot (https://www.example.com, 1.0)", c.userAgent)
+	})
+	t.Run("custom headers", func(t *testing.T) {
+		c := NewClient(WithHeader("X-Alpha", "1"), WithHeader("X-Alpha", "2"), WithHeader("X-Bravo", "3"))
+		assert.Equal(t, http.Header{"X-Alpha": {"1", "2"}, "X-Bravo": {"3"}}, c.headers)
+	})
+	t.Run("validation policy", func(t *testing.T) {
+		c := NewClient(WithValidationPolicy(ValidationStrict))
+		assert.Equal(t, ValidationStrict, c.validation)
+	})
+	t.Run("redacted bodies", func(t *testing.T) {
+		c := NewClient(WithRedactedBodies())
+		assert.True(t, c.redactBodies)
+		assert.Equal(t, redacted, c.loggedBody("body"))
+	})
+}
+
+func TestClient_WebhookList(t *testing.T) {
+	t.Run("should list webhooks in use ordered by ID", func(t *testing.T) {
+		c := NewClient()
+		wh1 := c.NewWebhook("https://discord.com/api/webhooks/456/token")
+		wh2 := c.NewWebhook("https://discord.com/api/webhooks/123/token")
+		wh3 := c.NewWebhook("https://discord.com/api/webhooks/456/token2")
+		assert.Equal(t, []*Webhook{wh2, wh1, wh3}, c.webhookList())
+	})
+	t.Run("should forget webhooks no longer in use", func(t *testing.T) {
+		c := NewClient()
+		for range 3 {
+			c.NewWebhook("https://discord.com/api/webhooks/123/token")
+		}
+		wh := c.NewWebhook("https://discord.com/api/webhooks/456/token")
+		runtime.GC()
+		assert.Equal(t, []*Webhook{wh}, c.webhookList())
+		c.mu.Lock()
+		assert.Len(t, c.webhooks, 1)
+		c.mu.Unlock()
+		runtime.KeepAlive(wh)
+	})
+}
+
+func TestClient_RequestURL(t *testing.T) {
+	const hook = "https://discord.com/api/webhooks/123/token"
+	cases := []struct {
+		name, proxy, url, want string
+	}{
+		{"no proxy", "", hook, hook},
+		{"proxy", "http://localhost:8080", hook, "http://localhost:8080/api/webhooks/123/token"},
+		{"proxy with path", "http://localhost:8080/dhook/", hook, "http://localhost:8080/dhook/api/webhooks/123/token"},
+		{"proxy with query", "http://localhost:8080", hook + "?thread_id=7", "http://localhost:8080/api/webhooks/123/token?thread_id=7"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var opts []ClientOption
+			if tc.proxy != "" {
+				opts = append(opts, WithRateLimitProxy(tc.proxy))
+			}
+			c := NewClient(opts...)
+			assert.Equal(t, tc.want, c.requestURL(tc.url))
+		})
+	}
 }
@@ -58,6 +58,69 @@ func TestWithWebhookRateLimit(t *testing.T) {
 		dhook.WithWebhookRateLimit(10, -time.Second)
 	})
 }
+func TestWithAdaptiveWebhookRateLimit(t *testing.T) {
+	assert.Panics(t, func() {
+		dhook.WithAdaptiveWebhookRateLimit(0, 10)
+	})
+	assert.Panics(t, func() {
+		dhook.WithAdaptiveWebhookRateLimit(10, 5)
+	})
+}
+
+func TestWithClock(t *testing.T) {
+	assert.Panics(t, func() {
+		dhook.WithClock(nil)
+	})
+}
+
+func TestWithGlobalRateLimiter(t *testing.T) {
+	assert.Panics(t, func() {
+		dhook.WithGlobalRateLimiter(nil)
+	})
+}
+
+func TestWithWebhookRateLimiter(t *testing.T) {
+	assert.Panics(t, func() {
+		dhook.WithWebhookRateLimiter(nil)
+	})
+}
+
+func TestWithRateLimitProxy(t *testing.T) {
+	assert.Panics(t, func() {
+		dhook.WithRateLimitProxy("")
+	})
+	assert.Panics(t, func() {
+		dhook.WithRateLimitProxy("localhost:8080")
+	})
+	assert.Panics(t, func() {
+		dhook.WithRateLimitProxy("ftp://localhost")
+	})
+}
+
+func TestWithValidationPolicy(t *testing.T) {
+	assert.Panics(t, func() {
+		dhook.WithValidationPolicy(dhook.ValidationFix + 1)
+	})
+}
+
+func TestWithCounting(t *testing.T) {
+	assert.Panics(t, func() {
+		dhook.WithCounting(dhook.CountUTF16 + 1)
+	})
+}
+
+func TestWithUserAgent(t *testing.T) {
+	assert.Panics(t, func() {
+		dhook.WithUserAgent("")
+	})
+}
+
+func TestWithHeader(t *testing.T) {
+	assert.Panics(t, func() {
+		dhook.WithHeader("", "value")
+	})
+}
+
 func TestClient_NewWebhook(t *testing.T) {
 	c := &dhook.Client{}
 	assert.Panics(t, func() {
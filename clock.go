@@ -0,0 +1,39 @@
+package dhook
+
+import (
+	"context"
+	"time"
+)
+
+// Clock represents a source of time for a client.
+//
+// All rate limits of a client use the clock for measuring and waiting,
+// which allows testing time-based behavior deterministically,
+// for example with the fake clock from the dhooktest package.
+// A custom clock can be configured for a client with [WithClock].
+// Implementations must be safe for concurrent use by multiple goroutines.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// Sleep pauses the current goroutine for duration d.
+	// It returns the context's error when ctx is done before.
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+// realClock is a clock using the system time.
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) Sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,28 @@
+package dhook
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRealClock(t *testing.T) {
+	t.Run("should return current time", func(t *testing.T) {
+		assert.WithinDuration(t, time.Now(), realClock{}.Now(), time.Second)
+	})
+	t.Run("should sleep", func(t *testing.T) {
+		start := time.Now()
+		err := realClock{}.Sleep(context.Background(), 50*time.Millisecond)
+		if assert.NoError(t, err) {
+			assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+		}
+	})
+	t.Run("should return error when context is done while sleeping", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		err := realClock{}.Sleep(ctx, time.Minute)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
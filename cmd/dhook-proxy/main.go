@@ -0,0 +1,66 @@
+/*
+Command dhook-proxy is a local rate limit proxy for Discord webhooks.
+
+It mimics the webhook endpoints of the Discord API and forwards all requests upstream,
+while applying the rate limits of the dhook library centrally.
+This allows several processes on the same host to post to the same webhooks
+without exceeding the rate limits together.
+
+Clients can be pointed at the proxy with [dhook.WithRateLimitProxy].
+
+Usage:
+
+	dhook-proxy [flags]
+
+The flags are:
+
+	-addr
+		Address to listen on (default "localhost:8080")
+	-upstream
+		Base URL of the upstream API (default "https://discord.com")
+	-timeout
+		HTTP timeout for upstream requests (default 30s)
+	-max-wait
+		Maximum duration a request waits for rate limits before it is answered
+		with HTTP status 429 (default 10s). This should be shorter than the timeout of the clients,
+		so that messages are not sent after a client has given up.
+	-global-requests, -global-period
+		Global rate limit (default 50 requests per 1s)
+	-webhook-requests, -webhook-period
+		Rate limit per webhook (default 30 requests per 60s)
+*/
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/ErikKalkoken/go-dhook"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:8080", "address to listen on")
+	upstream := flag.String("upstream", "https://discord.com", "base URL of the upstream API")
+	timeout := flag.Duration("timeout", 30*time.Second, "HTTP timeout for upstream requests")
+	maxWait := flag.Duration("max-wait", 10*time.Second, "maximum duration a request waits for rate limits")
+	globalRequests := flag.Int("global-requests", 50, "max requests for the global rate limit")
+	globalPeriod := flag.Duration("global-period", time.Second, "period for the global rate limit")
+	webhookRequests := flag.Int("webhook-requests", 30, "max requests for the webhook rate limit")
+	webhookPeriod := flag.Duration("webhook-period", 60*time.Second, "period for the webhook rate limit")
+	flag.Parse()
+
+	c := dhook.NewClient(
+		dhook.WithHTTPTimeout(*timeout),
+		dhook.WithGlobalRateLimit(*globalRequests, *globalPeriod),
+		dhook.WithWebhookRateLimit(*webhookRequests, *webhookPeriod),
+	)
+	p := newProxy(c, *upstream, *maxWait)
+	slog.Info("Starting proxy", "addr", *addr, "upstream", *upstream)
+	if err := http.ListenAndServe(*addr, p); err != nil {
+		slog.Error("Proxy terminated", "error", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ErikKalkoken/go-dhook"
+)
+
+// proxy is a HTTP handler which mimics the Discord webhook endpoints
+// and forwards requests upstream through a shared dhook client.
+// This type is safe for concurrent use by multiple goroutines.
+type proxy struct {
+	client   *dhook.Client
+	maxWait  time.Duration
+	mux      *http.ServeMux
+	upstream string
+
+	mu       sync.Mutex
+	webhooks map[string]*dhook.Webhook // by webhook ID and token
+}
+
+// newProxy returns a new proxy, which forwards requests to the upstream base URL.
+// Requests wait at most maxWait for the rate limits and are answered with HTTP status 429 otherwise.
+func newProxy(client *dhook.Client, upstream string, maxWait time.Duration) *proxy {
+	p := &proxy{
+		client:   client,
+		maxWait:  maxWait,
+		mux:      http.NewServeMux(),
+		upstream: strings.TrimSuffix(upstream, "/"),
+		webhooks: make(map[string]*dhook.Webhook),
+	}
+	p.mux.HandleFunc("POST /api/webhooks/{id}/{token}", p.handleExecute)
+	p.mux.HandleFunc("POST /api/{version}/webhooks/{id}/{token}", p.handleExecute)
+	return p
+}
+
+func (p *proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mux.ServeHTTP(w, r)
+}
+
+// webhook returns the webhook for a request. Webhooks are created on demand.
+//
+// Requests for the same webhook share its rate limits, regardless of the API version in their path.
+// They are forwarded to the endpoint of the first request for that webhook.
+func (p *proxy) webhook(r *http.Request) *dhook.Webhook {
+	key := r.PathValue("id") + "/" + r.PathValue("token")
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	wh, ok := p.webhooks[key]
+	if !ok {
+		wh = p.client.NewWebhook(p.upstream + r.URL.Path)
+		p.webhooks[key] = wh
+	}
+	return wh
+}
+
+// hopHeaders are the headers, which are not forwarded upstream.
+// These are hop-by-hop headers and headers, which are set by the HTTP client for each request.
+var hopHeaders = []string{
+	"Accept-Encoding",
+	"Connection",
+	"Content-Length",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// forwardedHeader returns the headers of a request, which are forwarded upstream.
+func forwardedHeader(r *http.Request) http.Header {
+	h := r.Header.Clone()
+	for _, f := range r.Header.Values("Connection") {
+		for k := range strings.SplitSeq(f, ",") {
+			h.Del(strings.TrimSpace(k))
+		}
+	}
+	for _, k := range hopHeaders {
+		h.Del(k)
+	}
+	return h
+}
+
+// handleExecute handles requests for executing a webhook.
+// The body, query parameters and headers are forwarded as is
+// and the response from upstream is returned as is.
+//
+// Requests, which would wait longer than the maximum wait for a rate limit, are answered with HTTP status 429.
+// This ensures that messages are not sent after the client has given up and possibly retries.
+func (p *proxy) handleExecute(w http.ResponseWriter, r *http.Request) {
+	dat, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeMessage(w, http.StatusBadRequest, "invalid body: "+err.Error())
+		return
+	}
+	query := r.URL.Query()
+	wait, _ := strconv.ParseBool(query.Get("wait"))
+	query.Del("wait")
+	wh := p.webhook(r)
+	body, err := wh.ExecuteJSON(dat, &dhook.WebhookExecuteOptions{
+		Header:           forwardedHeader(r),
+		MaxRateLimitWait: p.maxWait,
+		Query:            query,
+		Wait:             wait,
+	})
+	var errTooMany dhook.TooManyRequestsError
+	var errWouldBlock dhook.WouldBlockError
+	var errHTTP dhook.HTTPError
+	switch {
+	case errors.As(err, &errTooMany):
+		writeTooManyRequests(w, errTooMany.RetryAfter, errTooMany.Global, body)
+	case errors.As(err, &errWouldBlock):
+		writeTooManyRequests(w, errWouldBlock.RetryAfter, false, nil)
+	case errors.As(err, &errHTTP):
+		if len(body) == 0 {
+			writeMessage(w, errHTTP.Status, errHTTP.Message)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(errHTTP.Status)
+		w.Write(body)
+	case errors.Is(err, dhook.ErrInvalidMessage):
+		writeMessage(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		writeMessage(w, http.StatusGatewayTimeout, err.Error())
+	case err != nil:
+		writeMessage(w, http.StatusBadGateway, err.Error())
+	case wait:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// writeTooManyRequests writes a response with HTTP status 429.
+// It writes the body as is when it is not empty.
+func writeTooManyRequests(w http.ResponseWriter, retryAfter time.Duration, global bool, body []byte) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	if len(body) > 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write(body)
+		return
+	}
+	writeJSON(w, http.StatusTooManyRequests, map[string]any{
+		"message":     "You are being rate limited.",
+		"retry_after": retryAfter.Seconds(),
+		"global":      global,
+	})
+}
+
+func writeMessage(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]any{"message": message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ErikKalkoken/go-dhook"
+)
+
+// fakeUpstream is a fake Discord API which records all requests.
+type fakeUpstream struct {
+	handler http.HandlerFunc
+
+	mu       sync.Mutex
+	requests []fakeRequest
+}
+
+type fakeRequest struct {
+	path   string
+	query  string
+	header http.Header
+	body   string
+	at     time.Time
+}
+
+func (f *fakeUpstream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b, _ := io.ReadAll(r.Body)
+	f.mu.Lock()
+	f.requests = append(f.requests, fakeRequest{path: r.URL.Path, query: r.URL.RawQuery, header: r.Header, body: string(b), at: time.Now()})
+	f.mu.Unlock()
+	if f.handler != nil {
+		f.handler(w, r)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *fakeUpstream) calls() []fakeRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]fakeRequest(nil), f.requests...)
+}
+
+// startProxy starts a proxy with a fake upstream and returns the URL of the proxy.
+func startProxy(t *testing.T, upstream *fakeUpstream, opts ...dhook.ClientOption) string {
+	t.Helper()
+	return startProxyWithMaxWait(t, upstream, 10*time.Second, opts...)
+}
+
+// startProxyWithMaxWait starts a proxy with a fake upstream and a maximum wait for rate limits
+// and returns the URL of the proxy.
+func startProxyWithMaxWait(t *testing.T, upstream *fakeUpstream, maxWait time.Duration, opts ...dhook.ClientOption) string {
+	t.Helper()
+	us := httptest.NewServer(upstream)
+	t.Cleanup(us.Close)
+	ps := httptest.NewServer(newProxy(dhook.NewClient(opts...), us.URL, maxWait))
+	t.Cleanup(ps.Close)
+	return ps.URL
+}
+
+func TestProxy(t *testing.T) {
+	const hook = "https://discord.com/api/webhooks/123/token"
+	t.Run("should forward message to upstream", func(t *testing.T) {
+		upstream := &fakeUpstream{}
+		proxyURL := startProxy(t, upstream)
+		c := dhook.NewClient(dhook.WithRateLimitProxy(proxyURL))
+		wh := c.NewWebhook(hook)
+		_, err := wh.Execute(dhook.Message{Content: "content"}, nil)
+		if assert.NoError(t, err) {
+			calls := upstream.calls()
+			if assert.Len(t, calls, 1) {
+				assert.Equal(t, "/api/webhooks/123/token", calls[0].path)
+				assert.JSONEq(t, `{"content":"content"}`, calls[0].body)
+			}
+		}
+	})
+	t.Run("should forward versioned endpoints", func(t *testing.T) {
+		upstream := &fakeUpstream{}
+		proxyURL := startProxy(t, upstream)
+		c := dhook.NewClient(dhook.WithRateLimitProxy(proxyURL))
+		wh := c.NewWebhook("https://discord.com/api/v10/webhooks/123/token")
+		_, err := wh.Execute(dhook.Message{Content: "content"}, nil)
+		if assert.NoError(t, err) {
+			calls := upstream.calls()
+			if assert.Len(t, calls, 1) {
+				assert.Equal(t, "/api/v10/webhooks/123/token", calls[0].path)
+			}
+		}
+	})
+	t.Run("should return response body when waiting", func(t *testing.T) {
+		upstream := &fakeUpstream{handler: func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"id":"42"}`))
+		}}
+		proxyURL := startProxy(t, upstream)
+		c := dhook.NewClient(dhook.WithRateLimitProxy(proxyURL))
+		wh := c.NewWebhook(hook)
+		b, err := wh.Execute(dhook.Message{Content: "content"}, &dhook.WebhookExecuteOptions{Wait: true})
+		if assert.NoError(t, err) {
+			assert.JSONEq(t, `{"id":"42"}`, string(b))
+			assert.Equal(t, "wait=1", upstream.calls()[0].query)
+		}
+	})
+	t.Run("should return upstream errors", func(t *testing.T) {
+		upstream := &fakeUpstream{handler: func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}}
+		proxyURL := startProxy(t, upstream)
+		c := dhook.NewClient(dhook.WithRateLimitProxy(proxyURL))
+		wh := c.NewWebhook(hook)
+		_, err := wh.Execute(dhook.Message{Content: "content"}, nil)
+		var errHTTP dhook.HTTPError
+		if assert.ErrorAs(t, err, &errHTTP) {
+			assert.Equal(t, http.StatusNotFound, errHTTP.Status)
+		}
+	})
+	t.Run("should return upstream 429 as 429", func(t *testing.T) {
+		upstream := &fakeUpstream{handler: func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", "3")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"message":"You are being rate limited.","retry_after":2.5,"global":true}`))
+		}}
+		proxyURL := startProxy(t, upstream)
+		c := dhook.NewClient(dhook.WithRateLimitProxy(proxyURL))
+		wh := c.NewWebhook(hook)
+		_, err := wh.Execute(dhook.Message{Content: "content"}, nil)
+		var errTooMany dhook.TooManyRequestsError
+		if assert.ErrorAs(t, err, &errTooMany) {
+			assert.Equal(t, 3*time.Second, errTooMany.RetryAfter)
+			assert.True(t, errTooMany.Global)
+		}
+	})
+	t.Run("should reject invalid body", func(t *testing.T) {
+		upstream := &fakeUpstream{}
+		proxyURL := startProxy(t, upstream)
+		resp, err := http.Post(proxyURL+"/api/webhooks/123/token", "application/json", nil)
+		if assert.NoError(t, err) {
+			resp.Body.Close()
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			assert.Empty(t, upstream.calls())
+		}
+	})
+	t.Run("should forward body and query parameters as is", func(t *testing.T) {
+		upstream := &fakeUpstream{}
+		proxyURL := startProxy(t, upstream)
+		body := `{"content":"content","tts":true,"flags":4096,"components":[{"type":1}]}`
+		resp, err := http.Post(proxyURL+"/api/webhooks/123/token?thread_id=42&wait=true", "application/json", strings.NewReader(body))
+		if assert.NoError(t, err) {
+			resp.Body.Close()
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			calls := upstream.calls()
+			if assert.Len(t, calls, 1) {
+				assert.Equal(t, body, calls[0].body)
+				assert.Equal(t, "thread_id=42&wait=1", calls[0].query)
+			}
+		}
+	})
+	t.Run("should forward headers", func(t *testing.T) {
+		upstream := &fakeUpstream{}
+		proxyURL := startProxy(t, upstream)
+		c := dhook.NewClient(
+			dhook.WithRateLimitProxy(proxyURL),
+			dhook.WithUserAgent("DiscordBot (https://www.example.com, 1.0)"),
+			dhook.WithHeader("X-Alpha", "1"),
+			dhook.WithHooks(dhook.Hooks{
+				BeforeRequest: func(req *http.Request, _ dhook.Message) {
+					req.Header.Set("X-Trace", "abc")
+				},
+			}),
+		)
+		_, err := c.NewWebhook(hook).Execute(dhook.Message{Content: "content"}, nil)
+		if assert.NoError(t, err) {
+			calls := upstream.calls()
+			if assert.Len(t, calls, 1) {
+				assert.Equal(t, "DiscordBot (https://www.example.com, 1.0)", calls[0].header.Get("User-Agent"))
+				assert.Equal(t, "1", calls[0].header.Get("X-Alpha"))
+				assert.Equal(t, "abc", calls[0].header.Get("X-Trace"))
+			}
+		}
+	})
+	t.Run("should return upstream error response as is", func(t *testing.T) {
+		const body = `{"code":50035,"message":"Invalid Form Body"}`
+		upstream := &fakeUpstream{handler: func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(body))
+		}}
+		proxyURL := startProxy(t, upstream)
+		resp, err := http.Post(proxyURL+"/api/webhooks/123/token", "application/json", strings.NewReader(`{"content":"content"}`))
+		if assert.NoError(t, err) {
+			defer resp.Body.Close()
+			b, _ := io.ReadAll(resp.Body)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			assert.Equal(t, body, string(b))
+		}
+	})
+	t.Run("should answer 429 when rate limit wait would exceed maximum", func(t *testing.T) {
+		upstream := &fakeUpstream{}
+		proxyURL := startProxyWithMaxWait(t, upstream, 50*time.Millisecond, dhook.WithWebhookRateLimit(1, time.Minute))
+		var status []int
+		for range 2 {
+			resp, err := http.Post(proxyURL+"/api/webhooks/123/token", "application/json", strings.NewReader(`{"content":"content"}`))
+			if assert.NoError(t, err) {
+				resp.Body.Close()
+				status = append(status, resp.StatusCode)
+				if resp.StatusCode == http.StatusTooManyRequests {
+					assert.NotEmpty(t, resp.Header.Get("Retry-After"))
+				}
+			}
+		}
+		assert.Equal(t, []int{http.StatusNoContent, http.StatusTooManyRequests}, status)
+		assert.Len(t, upstream.calls(), 1)
+	})
+	t.Run("should apply webhook rate limit across API versions", func(t *testing.T) {
+		upstream := &fakeUpstream{}
+		proxyURL := startProxy(t, upstream, dhook.WithWebhookRateLimit(1, 200*time.Millisecond))
+		c := dhook.NewClient(dhook.WithRateLimitProxy(proxyURL))
+		start := time.Now()
+		for _, u := range []string{hook, "https://discord.com/api/v10/webhooks/123/token"} {
+			_, err := c.NewWebhook(u).Execute(dhook.Message{Content: "content"}, nil)
+			assert.NoError(t, err)
+		}
+		calls := upstream.calls()
+		if assert.Len(t, calls, 2) {
+			assert.GreaterOrEqual(t, calls[1].at.Sub(start), 150*time.Millisecond)
+		}
+	})
+	t.Run("should apply webhook rate limit across clients", func(t *testing.T) {
+		upstream := &fakeUpstream{}
+		proxyURL := startProxy(t, upstream, dhook.WithWebhookRateLimit(2, 200*time.Millisecond))
+		start := time.Now()
+		var wg sync.WaitGroup
+		for range 3 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				c := dhook.NewClient(dhook.WithRateLimitProxy(proxyURL))
+				wh := c.NewWebhook(hook)
+				_, err := wh.Execute(dhook.Message{Content: "content"}, nil)
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+		calls := upstream.calls()
+		if assert.Len(t, calls, 3) {
+			assert.GreaterOrEqual(t, calls[2].at.Sub(start), 150*time.Millisecond)
+		}
+	})
+}
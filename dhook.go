@@ -8,7 +8,14 @@ This is achieved by respecting the all rate limits when a request is sent to Dis
   - Per-route rate limit: A dynamic rate limit taken given in the response header
   - Webhook rate limit: An undocumented rate limit specific to webhooks
 
+When several webhooks of a client are waiting for the global rate limit,
+they are served fairly in round-robin order, which can be weighted per webhook with [WithWeight].
+
 Should the client still get rate limited it will block further requests to Discord
 for the time the rate limit is in effect to prevent further escalation.
 */
 package dhook
+
+// Version is the version of this library.
+// It is reported to Discord as part of the User-Agent header.
+const Version = "0.1.0"
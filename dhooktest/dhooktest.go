@@ -0,0 +1,69 @@
+// Package dhooktest provides utilities for testing code which uses dhook.
+package dhooktest
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// FakeClock is a fake clock for testing time-based behavior instantly and deterministically.
+//
+// It implements dhook.Clock and can be configured for a client with dhook.WithClock.
+// The time of a fake clock only changes when it is advanced or set.
+// Instead of pausing, Sleep advances the clock by the duration of the sleep.
+//
+// FakeClock is safe for concurrent use by multiple goroutines.
+type FakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	sleeps []time.Duration
+}
+
+// NewFakeClock returns a new fake clock, which starts at time t.
+func NewFakeClock(t time.Time) *FakeClock {
+	return &FakeClock{now: t}
+}
+
+// Now returns the current time of the clock.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Sleep advances the clock by d and returns immediately.
+// It returns the context's error when ctx is already done.
+func (c *FakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sleeps = append(c.sleeps, d)
+	if d > 0 {
+		c.now = c.now.Add(d)
+	}
+	return nil
+}
+
+// Advance advances the clock by d.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Set sets the clock to time t.
+func (c *FakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
+
+// Sleeps returns the durations of all calls to Sleep in order.
+func (c *FakeClock) Sleeps() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]time.Duration(nil), c.sleeps...)
+}
@@ -0,0 +1,48 @@
+package dhooktest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ErikKalkoken/go-dhook"
+	"github.com/ErikKalkoken/go-dhook/dhooktest"
+)
+
+var _ dhook.Clock = (*dhooktest.FakeClock)(nil)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	t.Run("should return start time", func(t *testing.T) {
+		c := dhooktest.NewFakeClock(start)
+		assert.Equal(t, start, c.Now())
+	})
+	t.Run("should advance time when sleeping", func(t *testing.T) {
+		c := dhooktest.NewFakeClock(start)
+		err := c.Sleep(context.Background(), time.Minute)
+		if assert.NoError(t, err) {
+			assert.Equal(t, start.Add(time.Minute), c.Now())
+			assert.Equal(t, []time.Duration{time.Minute}, c.Sleeps())
+		}
+	})
+	t.Run("should return error when context is done", func(t *testing.T) {
+		c := dhooktest.NewFakeClock(start)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := c.Sleep(ctx, time.Minute)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, start, c.Now())
+	})
+	t.Run("can advance time", func(t *testing.T) {
+		c := dhooktest.NewFakeClock(start)
+		c.Advance(time.Hour)
+		assert.Equal(t, start.Add(time.Hour), c.Now())
+	})
+	t.Run("can set time", func(t *testing.T) {
+		c := dhooktest.NewFakeClock(start)
+		c.Set(start.Add(-time.Hour))
+		assert.Equal(t, start.Add(-time.Hour), c.Now())
+	})
+}
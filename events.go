@@ -0,0 +1,182 @@
+package dhook
+
+import (
+	"sync"
+	"time"
+)
+
+// Event represents an activity of a client, which is published to subscribers.
+// See [Client.Subscribe] for details.
+//
+// Events are one of: [MessageSent], [MessageFailed], [RateLimitWaitStarted], [RateLimitWaitEnded],
+// [TooManyRequests], [WebhookGone] and [QueueOverflow].
+type Event interface {
+	event()
+}
+
+// MessageSent is published after a message was posted successfully.
+type MessageSent struct {
+	WebhookID string
+	Time      time.Time
+}
+
+// MessageFailed is published after posting a message failed.
+type MessageFailed struct {
+	WebhookID string
+	Time      time.Time
+	Err       error
+}
+
+// RateLimitWaitStarted is published when a message starts waiting for a rate limit.
+// Limiter is the name of the rate limit, i.e. "global", "api" or "webhook".
+type RateLimitWaitStarted struct {
+	WebhookID string
+	Time      time.Time
+	Limiter   string
+	Delay     time.Duration // Estimated wait time
+}
+
+// RateLimitWaitEnded is published when a message has finished waiting for a rate limit.
+type RateLimitWaitEnded struct {
+	WebhookID string
+	Time      time.Time
+	Limiter   string
+	Duration  time.Duration // Actual wait time
+}
+
+// TooManyRequests is published when Discord responded with HTTP status 429.
+type TooManyRequests struct {
+	WebhookID  string
+	Time       time.Time
+	RetryAfter time.Duration
+	Global     bool
+}
+
+// WebhookGone is published when Discord reports that a webhook does not exist (anymore),
+// e.g. because it was deleted.
+type WebhookGone struct {
+	WebhookID string
+	Time      time.Time
+}
+
+// QueueOverflow is published when the buffer of a subscriber was full
+// and older events had to be dropped.
+type QueueOverflow struct {
+	Time    time.Time
+	Dropped int // Number of events dropped
+}
+
+func (MessageSent) event()          {}
+func (MessageFailed) event()        {}
+func (RateLimitWaitStarted) event() {}
+func (RateLimitWaitEnded) event()   {}
+func (TooManyRequests) event()      {}
+func (WebhookGone) event()          {}
+func (QueueOverflow) event()        {}
+
+// Subscribe returns a channel, which receives the events of a client, and a function for unsubscribing.
+// Unsubscribing closes the channel.
+//
+// Events are buffered up to size events.
+// When the buffer of a subscriber is full, the oldest events are dropped
+// and replaced by a [QueueOverflow] event, so that a slow subscriber never blocks sending messages.
+// size must be at least 2.
+func (c *Client) Subscribe(size int) (<-chan Event, func()) {
+	if size < 2 {
+		panic("size must be at least 2")
+	}
+	if c.events == nil {
+		panic("can not use uninitialized Client")
+	}
+	return c.events.subscribe(size, c.clock)
+}
+
+// eventHub publishes events to all subscribers.
+type eventHub struct {
+	mu   sync.RWMutex
+	subs map[*subscriber]struct{}
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{subs: make(map[*subscriber]struct{})}
+}
+
+func (h *eventHub) subscribe(size int, clock Clock) (<-chan Event, func()) {
+	s := &subscriber{ch: make(chan Event, size), clock: clock}
+	h.mu.Lock()
+	h.subs[s] = struct{}{}
+	h.mu.Unlock()
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subs, s)
+			h.mu.Unlock()
+			s.close()
+		})
+	}
+	return s.ch, unsubscribe
+}
+
+// active reports whether there are any subscribers.
+func (h *eventHub) active() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subs) > 0
+}
+
+func (h *eventHub) publish(e Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for s := range h.subs {
+		s.publish(e)
+	}
+}
+
+// subscriber represents a subscriber with a bounded buffer of events.
+type subscriber struct {
+	clock Clock
+
+	mu     sync.Mutex
+	ch     chan Event
+	closed bool
+}
+
+// publish adds an event to the buffer without blocking.
+// When the buffer is full the oldest events are replaced by a [QueueOverflow] event.
+func (s *subscriber) publish(e Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.ch <- e:
+		return
+	default:
+	}
+	var dropped int
+	for len(s.ch) > cap(s.ch)-2 {
+		select {
+		case old := <-s.ch:
+			if o, ok := old.(QueueOverflow); ok {
+				dropped += o.Dropped
+			} else {
+				dropped++
+			}
+		default:
+		}
+	}
+	// The channel has room for both events, because only publish sends to it.
+	if dropped > 0 {
+		s.ch <- QueueOverflow{Time: s.clock.Now(), Dropped: dropped}
+	}
+	s.ch <- e
+}
+
+func (s *subscriber) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	close(s.ch)
+}
@@ -0,0 +1,62 @@
+package dhook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ErikKalkoken/go-dhook/dhooktest"
+)
+
+func TestSubscriber(t *testing.T) {
+	clock := dhooktest.NewFakeClock(time.Now())
+	receive := func(ch <-chan Event) []Event {
+		var s []Event
+		for len(ch) > 0 {
+			s = append(s, <-ch)
+		}
+		return s
+	}
+	t.Run("should buffer events", func(t *testing.T) {
+		h := newEventHub()
+		ch, _ := h.subscribe(3, clock)
+		h.publish(MessageSent{WebhookID: "1"})
+		h.publish(MessageSent{WebhookID: "2"})
+		assert.Equal(t, []Event{MessageSent{WebhookID: "1"}, MessageSent{WebhookID: "2"}}, receive(ch))
+	})
+	t.Run("should drop oldest events when buffer is full", func(t *testing.T) {
+		h := newEventHub()
+		ch, _ := h.subscribe(3, clock)
+		for _, id := range []string{"1", "2", "3", "4"} {
+			h.publish(MessageSent{WebhookID: id})
+		}
+		assert.Equal(t, []Event{
+			MessageSent{WebhookID: "3"},
+			QueueOverflow{Time: clock.Now(), Dropped: 2},
+			MessageSent{WebhookID: "4"},
+		}, receive(ch))
+	})
+	t.Run("should merge overflow events", func(t *testing.T) {
+		h := newEventHub()
+		ch, _ := h.subscribe(2, clock)
+		for _, id := range []string{"1", "2", "3", "4"} {
+			h.publish(MessageSent{WebhookID: id})
+		}
+		assert.Equal(t, []Event{
+			QueueOverflow{Time: clock.Now(), Dropped: 3},
+			MessageSent{WebhookID: "4"},
+		}, receive(ch))
+	})
+	t.Run("should close channel when unsubscribing", func(t *testing.T) {
+		h := newEventHub()
+		ch, unsubscribe := h.subscribe(2, clock)
+		assert.True(t, h.active())
+		unsubscribe()
+		unsubscribe()
+		assert.False(t, h.active())
+		h.publish(MessageSent{WebhookID: "1"})
+		_, ok := <-ch
+		assert.False(t, ok)
+	})
+}
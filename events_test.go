@@ -0,0 +1,102 @@
+package dhook_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ErikKalkoken/go-dhook"
+	"github.com/ErikKalkoken/go-dhook/dhooktest"
+)
+
+func TestClient_Subscribe(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	url := "https://discord.com/api/webhooks/123/token"
+	receive := func(ch <-chan dhook.Event) []dhook.Event {
+		var s []dhook.Event
+		for len(ch) > 0 {
+			s = append(s, <-ch)
+		}
+		return s
+	}
+	t.Run("should publish sent messages", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder("POST", url, httpmock.NewStringResponder(204, ""))
+		clock := dhooktest.NewFakeClock(time.Now())
+		c := dhook.NewClient(dhook.WithClock(clock))
+		ch, unsubscribe := c.Subscribe(10)
+		defer unsubscribe()
+		wh := c.NewWebhook(url)
+		_, err := wh.Execute(dhook.Message{Content: "content"}, nil)
+		if assert.NoError(t, err) {
+			assert.Equal(t, []dhook.Event{dhook.MessageSent{WebhookID: "123", Time: clock.Now()}}, receive(ch))
+		}
+	})
+	t.Run("should publish too many requests", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder(
+			"POST",
+			url,
+			httpmock.NewStringResponder(429, "").HeaderSet(http.Header{"Retry-After": []string{"3"}}),
+		)
+		clock := dhooktest.NewFakeClock(time.Now())
+		c := dhook.NewClient(dhook.WithClock(clock))
+		ch, unsubscribe := c.Subscribe(10)
+		defer unsubscribe()
+		wh := c.NewWebhook(url)
+		_, err := wh.Execute(dhook.Message{Content: "content"}, nil)
+		assert.Equal(t, []dhook.Event{
+			dhook.TooManyRequests{WebhookID: "123", Time: clock.Now(), RetryAfter: 3 * time.Second},
+			dhook.MessageFailed{WebhookID: "123", Time: clock.Now(), Err: err},
+		}, receive(ch))
+	})
+	t.Run("should publish gone webhooks", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder("POST", url, httpmock.NewStringResponder(404, ""))
+		clock := dhooktest.NewFakeClock(time.Now())
+		c := dhook.NewClient(dhook.WithClock(clock))
+		ch, unsubscribe := c.Subscribe(10)
+		defer unsubscribe()
+		wh := c.NewWebhook(url)
+		_, err := wh.Execute(dhook.Message{Content: "content"}, nil)
+		assert.Equal(t, []dhook.Event{
+			dhook.WebhookGone{WebhookID: "123", Time: clock.Now()},
+			dhook.MessageFailed{WebhookID: "123", Time: clock.Now(), Err: err},
+		}, receive(ch))
+	})
+	t.Run("should publish rate limit waits", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder("POST", url, httpmock.NewStringResponder(204, ""))
+		clock := dhooktest.NewFakeClock(time.Now())
+		c := dhook.NewClient(dhook.WithClock(clock), dhook.WithWebhookRateLimit(1, time.Minute))
+		wh := c.NewWebhook(url)
+		_, err := wh.Execute(dhook.Message{Content: "content"}, nil)
+		assert.NoError(t, err)
+		ch, unsubscribe := c.Subscribe(10)
+		defer unsubscribe()
+		start := clock.Now()
+		_, err = wh.Execute(dhook.Message{Content: "content"}, nil)
+		assert.NoError(t, err)
+		events := receive(ch)
+		if assert.Len(t, events, 3) {
+			started := events[0].(dhook.RateLimitWaitStarted)
+			assert.Equal(t, "webhook", started.Limiter)
+			assert.Equal(t, start, started.Time)
+			assert.Greater(t, started.Delay, time.Duration(0))
+			ended := events[1].(dhook.RateLimitWaitEnded)
+			assert.Equal(t, "webhook", ended.Limiter)
+			assert.Equal(t, clock.Now().Sub(start), ended.Duration)
+			assert.IsType(t, dhook.MessageSent{}, events[2])
+		}
+	})
+	t.Run("should panic when size is too small", func(t *testing.T) {
+		c := dhook.NewClient()
+		assert.Panics(t, func() {
+			c.Subscribe(1)
+		})
+	})
+}
@@ -1,6 +1,7 @@
 package dhook_test
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -56,6 +57,27 @@ func Example_complex() {
 	}
 }
 
+// This example shows how to build a message with a builder, which validates it.
+func Example_builder() {
+	c := dhook.NewClient()
+	wh := c.NewWebhook("YOUR-WEBHOOK-URL")
+	m, err := dhook.NewMessage().
+		Content("Content").
+		Embed(func(e *dhook.EmbedBuilder) {
+			e.Title("Title").
+				Field("First", "42", true).
+				Field("Second", "99", true).
+				Color(dhook.ColorOrange)
+		}).
+		Build()
+	if err != nil {
+		panic(err)
+	}
+	if _, err := wh.Execute(m, nil); err != nil {
+		panic(err)
+	}
+}
+
 // This example shows how to use execute options when sending a message.
 func Example_options() {
 	c := dhook.NewClient()
@@ -68,3 +90,15 @@ func Example_options() {
 	}
 	fmt.Println(string(b))
 }
+
+// This example shows how to drop a message instead of waiting for a rate limit.
+func Example_tryExecute() {
+	c := dhook.NewClient()
+	wh := c.NewWebhook("YOUR-WEBHOOK-URL")
+	_, err := wh.TryExecute(dhook.Message{Content: "Hello, World!"}, nil)
+	if errors.Is(err, dhook.ErrWouldBlock) {
+		fmt.Println("Message dropped")
+	} else if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,242 @@
+package dhook
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// fairQueue admits requests to a rate limiter fairly across flows, e.g. webhooks.
+//
+// Waiting requests are admitted in weighted round-robin order across flows
+// and in FIFO order within a flow.
+// A flow with weight n is admitted up to n times in a row before the next flow is served.
+// This ensures that a high-volume flow can not starve other flows.
+//
+// Requests with a higher priority are always admitted before requests with a lower priority.
+// Requests of the same flow with different priorities are queued as separate flows.
+//
+// This type is safe for concurrent use by multiple goroutines.
+type fairQueue struct {
+	limiter RateLimiter
+
+	mu         sync.Mutex
+	cancelWait context.CancelFunc // cancels the dispatcher's wait for the rate limiter
+	flows      map[flowKey]*flow  // flows with waiting requests
+	rings      map[int][]*flow    // flows with waiting requests in round-robin order by priority
+	running    bool               // whether the dispatcher is running
+}
+
+// flowKey identifies a flow.
+type flowKey struct {
+	key      string
+	priority int
+}
+
+// flow represents the waiting requests of one sender, e.g. a webhook.
+type flow struct {
+	id      flowKey
+	weight  int
+	credit  int // remaining admissions in the current round
+	tickets []*ticket
+}
+
+// ticket represents a waiting request.
+type ticket struct {
+	ready chan struct{}
+}
+
+func newFairQueue(limiter RateLimiter) *fairQueue {
+	q := &fairQueue{
+		limiter: limiter,
+		flows:   make(map[flowKey]*flow),
+		rings:   make(map[int][]*flow),
+	}
+	return q
+}
+
+// wait blocks until a request for the flow key is admitted by the rate limiter.
+// weight is the weight of the flow and must be at least 1.
+// Requests with a higher priority are admitted first.
+// It returns the context's error when ctx is done before the request is admitted.
+func (q *fairQueue) wait(ctx context.Context, key string, weight int, priority int) error {
+	q.mu.Lock()
+	if len(q.rings) == 0 && q.limiter.Reserve() == 0 {
+		q.mu.Unlock()
+		return nil // fast path: nobody else waiting and slot is free
+	}
+	t := &ticket{ready: make(chan struct{})}
+	id := flowKey{key: key, priority: priority}
+	f, ok := q.flows[id]
+	if !ok {
+		f = &flow{id: id, weight: weight, credit: weight}
+		q.flows[id] = f
+		q.rings[priority] = append(q.rings[priority], f)
+	}
+	f.weight = weight
+	f.tickets = append(f.tickets, t)
+	if !q.running {
+		q.running = true
+		go q.dispatch()
+	}
+	q.mu.Unlock()
+
+	select {
+	case <-t.ready:
+		return nil
+	case <-ctx.Done():
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	select {
+	case <-t.ready:
+		return nil // admitted in the meantime
+	default:
+	}
+	q.remove(f, t)
+	return ctx.Err()
+}
+
+// reserve admits a request and reports true when nobody is waiting and the rate limiter has a free slot.
+// Otherwise it returns the estimated duration until a request will be admitted.
+func (q *fairQueue) reserve(now time.Time) (time.Duration, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.rings) > 0 {
+		return q.delayLocked(now), false
+	}
+	d := q.limiter.Reserve()
+	return d, d == 0
+}
+
+// delay returns the estimated duration from now until a new request would be admitted.
+func (q *fairQueue) delay(now time.Time) time.Duration {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.delayLocked(now)
+}
+
+// delayLocked returns the estimated duration from now until a new request would be admitted.
+// Every waiting request is assumed to take an equal share of the rate limit period
+// after the next free slot of the rate limiter.
+// Caller must hold the lock.
+func (q *fairQueue) delayLocked(now time.Time) time.Duration {
+	s := q.limiter.State()
+	d := s.delay(now)
+	if n := q.lenLocked(); n > 0 && s.Limit > 0 {
+		d += time.Duration(n) * s.Period / time.Duration(s.Limit)
+	}
+	return d
+}
+
+// len returns the number of waiting requests.
+func (q *fairQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.lenLocked()
+}
+
+func (q *fairQueue) lenLocked() int {
+	var n int
+	for _, ring := range q.rings {
+		for _, f := range ring {
+			n += len(f.tickets)
+		}
+	}
+	return n
+}
+
+// dispatch admits waiting requests one by one, whenever the rate limiter has a free slot.
+// The next request to admit is chosen only after a slot becomes free,
+// so that requests arriving in the meantime get their fair chance.
+//
+// The dispatcher stops when no requests are waiting.
+// Its wait for the rate limiter is cancelled when the last waiting request gives up,
+// so that no slot is taken without a request to admit.
+func (q *fairQueue) dispatch() {
+	for {
+		q.mu.Lock()
+		if len(q.rings) == 0 {
+			q.running = false
+			q.mu.Unlock()
+			return
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		q.cancelWait = cancel
+		q.mu.Unlock()
+
+		err := q.limiter.Wait(ctx)
+
+		q.mu.Lock()
+		q.cancelWait = nil
+		cancel()
+		if err == nil {
+			if t := q.pop(); t != nil {
+				close(t.ready)
+			}
+		}
+		q.mu.Unlock()
+	}
+}
+
+// pop removes and returns the next ticket of the highest priority in round-robin order
+// or nil if there is none.
+// Caller must hold the lock.
+func (q *fairQueue) pop() *ticket {
+	if len(q.rings) == 0 {
+		return nil
+	}
+	priority := slices.Max(slices.Collect(maps.Keys(q.rings)))
+	ring := q.rings[priority]
+	f := ring[0]
+	t := f.tickets[0]
+	f.tickets = f.tickets[1:]
+	f.credit--
+	switch {
+	case len(f.tickets) == 0:
+		ring = ring[1:]
+		delete(q.flows, f.id)
+	case f.credit <= 0:
+		f.credit = f.weight
+		ring = append(ring[1:], f)
+	}
+	q.setRing(priority, ring)
+	return t
+}
+
+// setRing updates the ring of a priority and removes it when it is empty.
+// Caller must hold the lock.
+func (q *fairQueue) setRing(priority int, ring []*flow) {
+	if len(ring) == 0 {
+		delete(q.rings, priority)
+		return
+	}
+	q.rings[priority] = ring
+}
+
+// remove removes a ticket from its flow. Caller must hold the lock.
+func (q *fairQueue) remove(f *flow, t *ticket) {
+	for i, x := range f.tickets {
+		if x == t {
+			f.tickets = append(f.tickets[:i], f.tickets[i+1:]...)
+			break
+		}
+	}
+	if len(f.tickets) > 0 {
+		return
+	}
+	ring := q.rings[f.id.priority]
+	for i, x := range ring {
+		if x == f {
+			ring = append(ring[:i], ring[i+1:]...)
+			break
+		}
+	}
+	q.setRing(f.id.priority, ring)
+	delete(q.flows, f.id)
+	if len(q.rings) == 0 && q.cancelWait != nil {
+		q.cancelWait()
+	}
+}
@@ -0,0 +1,251 @@
+package dhook
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// tickLimiter is a rate limiter which allows one request per tick.
+type tickLimiter struct {
+	ticks chan struct{}
+}
+
+func (l *tickLimiter) Wait(ctx context.Context) error {
+	select {
+	case <-l.ticks:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *tickLimiter) Reserve() time.Duration {
+	select {
+	case <-l.ticks:
+		return 0
+	default:
+		return time.Second
+	}
+}
+
+func (l *tickLimiter) State() RateLimiterState {
+	return RateLimiterState{}
+}
+
+// fairQueueHarness allows testing a fairQueue with senders which continuously wait for admission.
+type fairQueueHarness struct {
+	admitted chan string
+	cancel   context.CancelFunc
+	ctx      context.Context
+	l        *tickLimiter
+	q        *fairQueue
+	oneShots map[string]int // number of one-shot senders per flow
+	queued   int            // expected number of queued requests
+}
+
+func newFairQueueHarness(tb testing.TB) *fairQueueHarness {
+	l := &tickLimiter{ticks: make(chan struct{})}
+	h := &fairQueueHarness{
+		admitted: make(chan string),
+		l:        l,
+		oneShots: make(map[string]int),
+		q:        newFairQueue(l),
+	}
+	h.ctx, h.cancel = context.WithCancel(context.Background())
+	tb.Cleanup(func() {
+		h.cancel()
+		close(l.ticks)
+	})
+	return h
+}
+
+// startSenders starts n senders for a flow and waits until they are all queued.
+func (h *fairQueueHarness) startSenders(key string, weight, n int) {
+	for range n {
+		go func() {
+			for {
+				if err := h.q.wait(h.ctx, key, weight, 0); err != nil {
+					return
+				}
+				select {
+				case h.admitted <- key:
+				case <-h.ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	h.queued += n
+	h.waitQueued(h.queued)
+}
+
+// startSender starts a sender for a flow which waits for admission once with a priority
+// and waits until it is queued.
+func (h *fairQueueHarness) startSender(key string, priority int) {
+	go func() {
+		if err := h.q.wait(h.ctx, key, 1, priority); err != nil {
+			return
+		}
+		h.admitted <- key
+	}()
+	h.oneShots[key]++
+	h.queued++
+	h.waitQueued(h.queued)
+}
+
+// waitQueued waits until n requests are queued.
+func (h *fairQueueHarness) waitQueued(n int) {
+	for h.q.len() != n {
+		runtime.Gosched()
+	}
+}
+
+// tick releases one slot and returns the key of the admitted flow.
+// It waits until the continuous senders have queued again.
+func (h *fairQueueHarness) tick() string {
+	h.l.ticks <- struct{}{}
+	key := <-h.admitted
+	if h.oneShots[key] > 0 {
+		h.oneShots[key]--
+		h.queued--
+	}
+	h.waitQueued(h.queued)
+	return key
+}
+
+func TestFairQueue(t *testing.T) {
+	t.Run("should admit immediately when nobody is waiting", func(t *testing.T) {
+		q := newFairQueue(newLimiter(1, time.Minute, "", slog.Default(), realClock{}))
+		err := q.wait(context.Background(), "a", 1, 0)
+		assert.NoError(t, err)
+	})
+	t.Run("should admit in FIFO order within a flow", func(t *testing.T) {
+		q := newFairQueue(newLimiter(1, 100*time.Millisecond, "", slog.Default(), realClock{}))
+		q.wait(context.Background(), "a", 1, 0)
+		done := make(chan int, 2)
+		for i := range 2 {
+			go func() {
+				q.wait(context.Background(), "a", 1, 0)
+				done <- i
+			}()
+			for q.len() != i+1 {
+				runtime.Gosched()
+			}
+		}
+		assert.Equal(t, 0, <-done)
+		assert.Equal(t, 1, <-done)
+	})
+	t.Run("should serve flows in round-robin order", func(t *testing.T) {
+		h := newFairQueueHarness(t)
+		h.startSenders("a", 1, 3)
+		h.startSenders("b", 1, 3)
+		var got []string
+		for range 4 {
+			got = append(got, h.tick())
+		}
+		assert.Equal(t, []string{"a", "b", "a", "b"}, got)
+	})
+	t.Run("should serve flows according to their weight", func(t *testing.T) {
+		h := newFairQueueHarness(t)
+		h.startSenders("a", 2, 3)
+		h.startSenders("b", 1, 3)
+		var got []string
+		for range 6 {
+			got = append(got, h.tick())
+		}
+		assert.Equal(t, []string{"a", "a", "b", "a", "a", "b"}, got)
+	})
+	t.Run("should not delay a new flow by more than one slot", func(t *testing.T) {
+		h := newFairQueueHarness(t)
+		h.startSenders("chatty", 1, 10)
+		h.tick()
+		h.startSender("critical", 0)
+		var slots int
+		for h.tick() != "critical" {
+			slots++
+		}
+		assert.LessOrEqual(t, slots, 1)
+	})
+	t.Run("should serve higher priority first", func(t *testing.T) {
+		h := newFairQueueHarness(t)
+		h.startSenders("chatty", 1, 10)
+		h.startSender("low", -1)
+		h.startSender("high", 1)
+		h.startSender("urgent", 2)
+		var got []string
+		for range 4 {
+			got = append(got, h.tick())
+		}
+		assert.Equal(t, []string{"urgent", "high", "chatty", "chatty"}, got)
+	})
+	t.Run("should not take slot when the only waiting request gives up", func(t *testing.T) {
+		l := newLimiter(1, 200*time.Millisecond, "", slog.Default(), realClock{})
+		q := newFairQueue(l)
+		start := time.Now()
+		q.wait(context.Background(), "a", 1, 0)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		err := q.wait(ctx, "a", 1, 0)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		for running := true; running; {
+			runtime.Gosched() // wait for dispatcher to stop
+			q.mu.Lock()
+			running = q.running
+			q.mu.Unlock()
+		}
+		time.Sleep(time.Until(start.Add(250 * time.Millisecond)))
+		assert.Zero(t, l.Reserve())
+	})
+	t.Run("should remove request when context is done", func(t *testing.T) {
+		q := newFairQueue(&tickLimiter{ticks: make(chan struct{})})
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		err := q.wait(ctx, "a", 1, 0)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, 0, q.len())
+	})
+}
+
+func TestFairQueue_Reserve(t *testing.T) {
+	t.Run("should admit when nobody is waiting and slot is free", func(t *testing.T) {
+		q := newFairQueue(newLimiter(1, time.Minute, "", slog.Default(), realClock{}))
+		_, ok := q.reserve(time.Now())
+		assert.True(t, ok)
+		d, ok := q.reserve(time.Now())
+		assert.False(t, ok)
+		assert.InDelta(t, time.Minute, d, float64(time.Second))
+	})
+	t.Run("should not admit when others are waiting", func(t *testing.T) {
+		h := newFairQueueHarness(t)
+		h.startSenders("a", 1, 1)
+		_, ok := h.q.reserve(time.Now())
+		assert.False(t, ok)
+	})
+}
+
+func TestFairQueue_Delay(t *testing.T) {
+	t.Run("should report delay of rate limiter", func(t *testing.T) {
+		q := newFairQueue(newLimiter(1, time.Minute, "", slog.Default(), realClock{}))
+		assert.Zero(t, q.delay(time.Now()))
+		q.wait(context.Background(), "a", 1, 0)
+		assert.InDelta(t, time.Minute, q.delay(time.Now()), float64(time.Second))
+	})
+	t.Run("should add share of period for every waiting request", func(t *testing.T) {
+		l := newLimiter(1, time.Minute, "", slog.Default(), realClock{})
+		q := newFairQueue(l)
+		q.wait(context.Background(), "a", 1, 0)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go q.wait(ctx, "b", 1, 0)
+		go q.wait(ctx, "c", 1, 0)
+		for q.len() != 2 {
+			runtime.Gosched()
+		}
+		assert.InDelta(t, 3*time.Minute, q.delay(time.Now()), float64(time.Second))
+	})
+}
@@ -0,0 +1,163 @@
+package dhook
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// ellipsisDefault is the default marker for truncated text.
+const ellipsisDefault = "…"
+
+// linkRx matches markdown links, which must not be truncated.
+var linkRx = regexp.MustCompile(`\[[^\]]*\]\([^)\s]*\)`)
+
+// Truncation represents a part of a message, which was truncated or dropped to fit Discord's limits.
+type Truncation struct {
+	Path    string // JSON path of the part, e.g. "embeds[1].fields[3].value"
+	Limit   int    // Limit of the part
+	Length  int    // Original length of the part
+	Dropped bool   // Whether the part was dropped completely
+}
+
+func (t Truncation) String() string {
+	if t.Dropped {
+		return t.Path + " dropped"
+	}
+	return fmt.Sprintf("%s truncated from %d to %d", t.Path, t.Length, t.Limit)
+}
+
+// Fitter truncates messages to fit Discord's limits.
+//
+// Texts exceeding their limit are truncated and marked with an ellipsis.
+// Truncation never splits characters, markdown links, mentions or custom emojis
+// and closes code blocks, which would otherwise be left open.
+//
+// Excess embeds and fields are dropped from the end.
+// When the combined size of all embeds is still too large,
+// fields are dropped from the last embed, then the last embed is dropped
+// and finally the description of the only remaining embed is truncated.
+//
+// The zero value is ready for use.
+type Fitter struct {
+	// How characters are counted. The default is [CountCodePoints].
+	Counting Counting
+
+	// Marker appended to truncated texts. The default is "…".
+	// It is left out for texts with a limit shorter than the marker.
+	Ellipsis string
+}
+
+// Fit returns a copy of a message, which is truncated to fit Discord's limits,
+// and reports what was truncated. See [Fitter] for details.
+func (m Message) Fit() (Message, []Truncation) {
+	return Fitter{}.Fit(m)
+}
+
+// Fit returns a copy of a message, which is truncated to fit Discord's limits,
+// and reports what was truncated.
+func (f Fitter) Fit(m Message) (Message, []Truncation) {
+	var report []Truncation
+	text := func(s *string, path string, limit int) {
+		if n := f.Counting.Len(*s); n > limit {
+			*s = f.truncate(*s, limit)
+			report = append(report, Truncation{Path: path, Limit: limit, Length: n})
+		}
+	}
+	drop := func(path string, limit int) {
+		report = append(report, Truncation{Path: path, Limit: limit, Dropped: true})
+	}
+	text(&m.Content, "content", contentLength)
+	text(&m.Username, "username", usernameLength)
+	for i := embedsQuantity; i < len(m.Embeds); i++ {
+		drop(fmt.Sprintf("embeds[%d]", i), embedsQuantity)
+	}
+	m.Embeds = slices.Clone(m.Embeds[:min(len(m.Embeds), embedsQuantity)])
+	for i := range m.Embeds {
+		em := &m.Embeds[i]
+		p := fmt.Sprintf("embeds[%d]", i)
+		text(&em.Title, p+".title", titleLength)
+		text(&em.Description, p+".description", descriptionLength)
+		text(&em.Author.Name, p+".author.name", nameLength)
+		text(&em.Footer.Text, p+".footer.text", footerTextLength)
+		for j := fieldsQuantity; j < len(em.Fields); j++ {
+			drop(fmt.Sprintf("%s.fields[%d]", p, j), fieldsQuantity)
+		}
+		em.Fields = slices.Clone(em.Fields[:min(len(em.Fields), fieldsQuantity)])
+		for j := range em.Fields {
+			text(&em.Fields[j].Name, fmt.Sprintf("%s.fields[%d].name", p, j), nameLength)
+			text(&em.Fields[j].Value, fmt.Sprintf("%s.fields[%d].value", p, j), fieldValueLength)
+		}
+	}
+	for len(m.Embeds) > 0 {
+		excess := m.embedsSize(f.Counting) - embedCombinedLength
+		if excess <= 0 {
+			break
+		}
+		i := len(m.Embeds) - 1
+		em := &m.Embeds[i]
+		switch {
+		case len(em.Fields) > 0:
+			j := len(em.Fields) - 1
+			drop(fmt.Sprintf("embeds[%d].fields[%d]", i, j), embedCombinedLength)
+			em.Fields = em.Fields[:j]
+		case len(m.Embeds) > 1:
+			drop(fmt.Sprintf("embeds[%d]", i), embedCombinedLength)
+			m.Embeds = m.Embeds[:i]
+		case em.Description == "":
+			return m, report // nothing left to truncate
+		default:
+			limit := f.Counting.Len(em.Description) - excess
+			if limit <= f.Counting.Len(f.ellipsis()) {
+				drop("embeds[0].description", embedCombinedLength)
+				em.Description = ""
+			} else {
+				text(&em.Description, "embeds[0].description", limit)
+			}
+		}
+	}
+	return m, report
+}
+
+func (f Fitter) ellipsis() string {
+	if f.Ellipsis == "" {
+		return ellipsisDefault
+	}
+	return f.Ellipsis
+}
+
+// truncate returns s truncated to at most limit characters including the ellipsis.
+// The ellipsis is left out when it does not fit into the limit.
+func (f Fitter) truncate(s string, limit int) string {
+	ellipsis := f.ellipsis()
+	if f.Counting.Len(ellipsis) > limit {
+		ellipsis = ""
+	}
+	closing := "\n" + codeFence
+	r := []rune(s)
+	var spans [][2]int
+	for _, rx := range []*regexp.Regexp{linkRx, protectedRx} {
+		for _, loc := range rx.FindAllStringIndex(s, -1) {
+			start := utf8.RuneCountInString(s[:loc[0]])
+			spans = append(spans, [2]int{start, start + utf8.RuneCountInString(s[loc[0]:loc[1]])})
+		}
+	}
+	for _, reserve := range []int{f.Counting.Len(ellipsis), f.Counting.Len(ellipsis + closing)} {
+		c := f.Counting.fit(r, limit-reserve)
+		for _, sp := range spans {
+			if sp[0] < c && c < sp[1] {
+				c = sp[0]
+			}
+		}
+		t := string(r[:c])
+		if strings.Count(t, codeFence)%2 == 0 {
+			return t + ellipsis
+		}
+		if reserve > f.Counting.Len(ellipsis) {
+			return t + ellipsis + closing
+		}
+	}
+	panic("unreachable")
+}
@@ -0,0 +1,152 @@
+package dhook_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ErikKalkoken/go-dhook"
+)
+
+func TestMessage_Fit(t *testing.T) {
+	t.Run("should not change valid message", func(t *testing.T) {
+		m := dhook.Message{Content: "content", Embeds: []dhook.Embed{{Title: "title"}}}
+		got, report := m.Fit()
+		assert.Equal(t, m, got)
+		assert.Empty(t, report)
+	})
+	t.Run("should truncate texts", func(t *testing.T) {
+		m := dhook.Message{
+			Content:  strings.Repeat("x", 2001),
+			Username: strings.Repeat("x", 81),
+			Embeds: []dhook.Embed{{
+				Title:       strings.Repeat("x", 257),
+				Description: strings.Repeat("x", 4097),
+				Author:      dhook.Author{Name: strings.Repeat("x", 257)},
+				Footer:      dhook.Footer{Text: "footer"},
+				Fields:      []dhook.Field{{Name: strings.Repeat("x", 257), Value: strings.Repeat("x", 1025)}},
+			}},
+		}
+		got, report := m.Fit()
+		assert.Equal(t, strings.Repeat("x", 1999)+"…", got.Content)
+		assert.Equal(t, 80, len([]rune(got.Username)))
+		assert.Equal(t, 256, len([]rune(got.Embeds[0].Title)))
+		assert.Equal(t, 256, len([]rune(got.Embeds[0].Author.Name)))
+		assert.Equal(t, 256, len([]rune(got.Embeds[0].Fields[0].Name)))
+		assert.Equal(t, 1024, len([]rune(got.Embeds[0].Fields[0].Value)))
+		assert.Equal(t, "footer", got.Embeds[0].Footer.Text)
+		assert.NoError(t, got.Validate())
+		var paths []string
+		for _, x := range report {
+			paths = append(paths, x.Path)
+		}
+		assert.Equal(t, []string{
+			"content",
+			"username",
+			"embeds[0].title",
+			"embeds[0].description",
+			"embeds[0].author.name",
+			"embeds[0].fields[0].name",
+			"embeds[0].fields[0].value",
+		}, paths)
+		assert.Equal(t, dhook.Truncation{Path: "content", Limit: 2000, Length: 2001}, report[0])
+		assert.Equal(t, strings.Repeat("x", 4097), m.Embeds[0].Description, "original not changed")
+	})
+	t.Run("should drop excess embeds and fields", func(t *testing.T) {
+		m := dhook.Message{Embeds: make([]dhook.Embed, 11)}
+		for range 26 {
+			m.Embeds[0].Fields = append(m.Embeds[0].Fields, dhook.Field{Name: "name", Value: "value"})
+		}
+		got, report := m.Fit()
+		assert.Len(t, got.Embeds, 10)
+		assert.Len(t, got.Embeds[0].Fields, 25)
+		assert.Equal(t, []dhook.Truncation{
+			{Path: "embeds[10]", Limit: 10, Dropped: true},
+			{Path: "embeds[0].fields[25]", Limit: 25, Dropped: true},
+		}, report)
+		assert.Len(t, m.Embeds[0].Fields, 26, "original not changed")
+	})
+	t.Run("should drop fields, then embeds to fit combined size", func(t *testing.T) {
+		m := dhook.Message{Embeds: []dhook.Embed{
+			{Description: strings.Repeat("x", 4000)},
+			{Description: strings.Repeat("x", 2500), Fields: []dhook.Field{{Name: "n", Value: strings.Repeat("x", 999)}}},
+		}}
+		got, report := m.Fit()
+		assert.Len(t, got.Embeds, 1)
+		assert.Equal(t, []dhook.Truncation{
+			{Path: "embeds[1].fields[0]", Limit: 6000, Dropped: true},
+			{Path: "embeds[1]", Limit: 6000, Dropped: true},
+		}, report)
+		assert.NoError(t, got.Validate())
+	})
+	t.Run("should truncate description of last embed to fit combined size", func(t *testing.T) {
+		m := dhook.Message{Embeds: []dhook.Embed{{
+			Title:       strings.Repeat("x", 256),
+			Description: strings.Repeat("x", 4096),
+			Footer:      dhook.Footer{Text: strings.Repeat("x", 2048)},
+		}}}
+		got, _ := m.Fit()
+		assert.NoError(t, got.Validate())
+	})
+}
+
+func TestFitter_Fit(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"custom ellipsis", strings.Repeat("x", 2001), strings.Repeat("x", 1995) + "[...]"},
+		{"close code block", "```go\n" + strings.Repeat("x", 2000) + "\n```", "```go\n" + strings.Repeat("x", 1985) + "[...]\n```"},
+		{"keep link", strings.Repeat("x", 1990) + "[link](https://www.example.com)", strings.Repeat("x", 1990) + "[...]"},
+		{"keep mention", strings.Repeat("x", 1990) + "<@123456789>", strings.Repeat("x", 1990) + "[...]"},
+		{"keep characters", strings.Repeat("😀", 2001), strings.Repeat("😀", 1995) + "[...]"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, _ := dhook.Fitter{Ellipsis: "[...]"}.Fit(dhook.Message{Content: tc.content})
+			assert.Equal(t, tc.want, got.Content)
+		})
+	}
+}
+
+func TestFitter_FitLongEllipsis(t *testing.T) {
+	f := dhook.Fitter{Ellipsis: strings.Repeat(".", 100)}
+	got, _ := f.Fit(dhook.Message{Content: "content", Username: strings.Repeat("x", 200)})
+	assert.Equal(t, strings.Repeat("x", 80), got.Username)
+	assert.NoError(t, got.Validate())
+}
+
+func TestFitter_FitCountingUTF16(t *testing.T) {
+	f := dhook.Fitter{Counting: dhook.CountUTF16, Ellipsis: "[...]"}
+	got, report := f.Fit(dhook.Message{Content: strings.Repeat("😀", 1001)})
+	assert.Equal(t, strings.Repeat("😀", 997)+"[...]", got.Content)
+	assert.Equal(t, []dhook.Truncation{{Path: "content", Limit: 2000, Length: 2002}}, report)
+}
+
+func TestWebhook_ExecuteFit(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	url := "https://www.example.com/hook"
+	httpmock.RegisterResponder("POST", url, func(req *http.Request) (*http.Response, error) {
+		var m dhook.Message
+		if err := json.NewDecoder(req.Body).Decode(&m); err != nil {
+			return nil, err
+		}
+		if err := m.Validate(); err != nil {
+			return httpmock.NewStringResponse(400, ""), nil
+		}
+		return httpmock.NewStringResponse(204, ""), nil
+	})
+	c := dhook.NewClient()
+	wh := c.NewWebhook(url)
+	m := dhook.Message{Content: strings.Repeat("x", 2001)}
+	_, err := wh.Execute(m, nil)
+	assert.Error(t, err)
+	_, err = wh.Execute(m, &dhook.WebhookExecuteOptions{Fit: true})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,83 @@
+package dhook
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// gate is a mutual exclusion lock, which is handed over to waiters by priority.
+//
+// Waiters with a higher priority acquire the lock before waiters with a lower priority
+// and waiters with the same priority acquire it in FIFO order.
+// The zero value is an unlocked gate.
+// This type is safe for concurrent use by multiple goroutines.
+type gate struct {
+	mu      sync.Mutex
+	locked  bool
+	waiters []*gateWaiter // ordered by priority descending, then FIFO
+}
+
+// gateWaiter represents a goroutine waiting for a gate.
+type gateWaiter struct {
+	priority int
+	ready    chan struct{} // closed when the lock is handed over
+}
+
+// lock blocks until the lock is acquired.
+// It returns the context's error when ctx is done before the lock is acquired.
+func (g *gate) lock(ctx context.Context, priority int) error {
+	g.mu.Lock()
+	if !g.locked {
+		g.locked = true
+		g.mu.Unlock()
+		return nil
+	}
+	w := &gateWaiter{priority: priority, ready: make(chan struct{})}
+	i := len(g.waiters)
+	for i > 0 && g.waiters[i-1].priority < priority {
+		i--
+	}
+	g.waiters = slices.Insert(g.waiters, i, w)
+	g.mu.Unlock()
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	select {
+	case <-w.ready:
+		return nil // the lock was handed over in the meantime
+	default:
+	}
+	g.waiters = slices.DeleteFunc(g.waiters, func(x *gateWaiter) bool {
+		return x == w
+	})
+	return ctx.Err()
+}
+
+// tryLock tries to acquire the lock without waiting and reports whether it succeeded.
+func (g *gate) tryLock() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.locked {
+		return false
+	}
+	g.locked = true
+	return true
+}
+
+// unlock releases the lock or hands it over to the next waiter.
+func (g *gate) unlock() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.waiters) == 0 {
+		g.locked = false
+		return
+	}
+	w := g.waiters[0]
+	g.waiters = g.waiters[1:]
+	close(w.ready)
+}
@@ -0,0 +1,68 @@
+package dhook
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// waiting returns the number of goroutines waiting for the gate.
+func (g *gate) waiting() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.waiters)
+}
+
+func TestGate(t *testing.T) {
+	t.Run("should lock and unlock", func(t *testing.T) {
+		var g gate
+		assert.NoError(t, g.lock(context.Background(), 0))
+		assert.False(t, g.tryLock())
+		g.unlock()
+		assert.True(t, g.tryLock())
+	})
+	t.Run("should hand over lock by priority and then in FIFO order", func(t *testing.T) {
+		var g gate
+		g.tryLock()
+		got := make(chan int)
+		for i, priority := range []int{0, 1, 0, 2, 1} {
+			go func() {
+				g.lock(context.Background(), priority)
+				got <- i
+			}()
+			for g.waiting() != i+1 {
+				runtime.Gosched()
+			}
+		}
+		var order []int
+		for range 5 {
+			g.unlock()
+			order = append(order, <-got)
+		}
+		assert.Equal(t, []int{3, 1, 4, 0, 2}, order)
+	})
+	t.Run("should not hand over lock when tryLock would fail", func(t *testing.T) {
+		var g gate
+		g.tryLock()
+		go g.lock(context.Background(), 0)
+		for g.waiting() != 1 {
+			runtime.Gosched()
+		}
+		g.unlock()
+		assert.False(t, g.tryLock())
+	})
+	t.Run("should stop waiting when context is done", func(t *testing.T) {
+		var g gate
+		g.tryLock()
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		err := g.lock(ctx, 0)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Zero(t, g.waiting())
+		g.unlock()
+		assert.True(t, g.tryLock())
+	})
+}
@@ -0,0 +1,70 @@
+package dhook
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Hooks represents callbacks for observing the requests of a client,
+// e.g. for emitting tracing spans or custom metrics.
+//
+// All callbacks are optional. They are called synchronously from the goroutine sending a message
+// and must therefore return quickly and be safe for concurrent use.
+//
+// Callbacks receive the context of the message or its request,
+// from which the correlation ID can be retrieved with [CorrelationID].
+type Hooks struct {
+	// BeforeRequest is called right before a request is sent to Discord.
+	// It may modify the request, e.g. to inject headers with trace IDs.
+	BeforeRequest func(req *http.Request, message Message)
+
+	// AfterResponse is called after a request to Discord has completed with the duration of the request
+	// and the error returned to the caller, if any.
+	// resp is nil when no response was received. The response body is already closed.
+	AfterResponse func(ctx context.Context, resp *http.Response, duration time.Duration, err error)
+
+	// OnRateLimitWait is called after a message had to wait for a rate limit
+	// with the name of the rate limit ("global", "api" or "webhook") and the duration waited.
+	OnRateLimitWait func(ctx context.Context, limiter string, duration time.Duration)
+
+	// OnTooManyRequests is called when Discord responded with HTTP status 429.
+	OnTooManyRequests func(ctx context.Context, err TooManyRequestsError)
+}
+
+type correlationIDKey struct{}
+
+// CorrelationID returns the correlation ID from the context of a message or its request
+// or an empty string if it has none. See also [WebhookExecuteOptions].
+//
+// For example the correlation ID can be retrieved in [Hooks.BeforeRequest] with:
+//
+//	id := dhook.CorrelationID(req.Context())
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+func (h Hooks) beforeRequest(req *http.Request, message Message) {
+	if h.BeforeRequest != nil {
+		h.BeforeRequest(req, message)
+	}
+}
+
+func (h Hooks) afterResponse(ctx context.Context, resp *http.Response, duration time.Duration, err error) {
+	if h.AfterResponse != nil {
+		h.AfterResponse(ctx, resp, duration, err)
+	}
+}
+
+func (h Hooks) onRateLimitWait(ctx context.Context, limiter string, duration time.Duration) {
+	if h.OnRateLimitWait != nil && duration > 0 {
+		h.OnRateLimitWait(ctx, limiter, duration)
+	}
+}
+
+func (h Hooks) onTooManyRequests(ctx context.Context, err TooManyRequestsError) {
+	if h.OnTooManyRequests != nil {
+		h.OnTooManyRequests(ctx, err)
+	}
+}
@@ -0,0 +1,157 @@
+package dhook_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ErikKalkoken/go-dhook"
+	"github.com/ErikKalkoken/go-dhook/dhooktest"
+)
+
+func TestHooks(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	url := "https://www.example.com/hook"
+	t.Run("can inject headers before request", func(t *testing.T) {
+		httpmock.Reset()
+		var traceID string
+		httpmock.RegisterResponder("POST", url, func(req *http.Request) (*http.Response, error) {
+			traceID = req.Header.Get("X-Trace-ID")
+			return httpmock.NewStringResponse(204, ""), nil
+		})
+		var content string
+		c := dhook.NewClient(dhook.WithHooks(dhook.Hooks{
+			BeforeRequest: func(req *http.Request, message dhook.Message) {
+				req.Header.Set("X-Trace-ID", "abc")
+				content = message.Content
+			},
+		}))
+		wh := c.NewWebhook(url)
+		_, err := wh.Execute(dhook.Message{Content: "content"}, nil)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "abc", traceID)
+			assert.Equal(t, "content", content)
+		}
+	})
+	t.Run("should report responses", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder("POST", url, httpmock.NewStringResponder(400, ""))
+		var status int
+		var err2 error
+		c := dhook.NewClient(dhook.WithHooks(dhook.Hooks{
+			AfterResponse: func(_ context.Context, resp *http.Response, duration time.Duration, err error) {
+				status = resp.StatusCode
+				err2 = err
+			},
+		}))
+		wh := c.NewWebhook(url)
+		_, err := wh.Execute(dhook.Message{Content: "content"}, nil)
+		assert.Error(t, err)
+		assert.Equal(t, 400, status)
+		assert.Equal(t, err, err2)
+	})
+	t.Run("should report failed requests", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder("POST", url, httpmock.NewErrorResponder(assert.AnError))
+		var called bool
+		var resp2 *http.Response
+		var err2 error
+		c := dhook.NewClient(dhook.WithHooks(dhook.Hooks{
+			AfterResponse: func(_ context.Context, resp *http.Response, duration time.Duration, err error) {
+				called = true
+				resp2 = resp
+				err2 = err
+			},
+		}))
+		wh := c.NewWebhook(url)
+		wh.Execute(dhook.Message{Content: "content"}, nil)
+		assert.True(t, called)
+		assert.Nil(t, resp2)
+		assert.ErrorIs(t, err2, assert.AnError)
+	})
+	t.Run("should report too many requests", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder(
+			"POST",
+			url,
+			httpmock.NewStringResponder(429, "").HeaderSet(http.Header{"Retry-After": []string{"3"}}),
+		)
+		var got dhook.TooManyRequestsError
+		c := dhook.NewClient(dhook.WithHooks(dhook.Hooks{
+			OnTooManyRequests: func(_ context.Context, err dhook.TooManyRequestsError) {
+				got = err
+			},
+		}))
+		wh := c.NewWebhook(url)
+		wh.Execute(dhook.Message{Content: "content"}, nil)
+		assert.Equal(t, dhook.TooManyRequestsError{RetryAfter: 3 * time.Second}, got)
+	})
+	t.Run("should report rate limit waits", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder("POST", url, httpmock.NewStringResponder(204, ""))
+		clock := dhooktest.NewFakeClock(time.Now())
+		waits := make(map[string]time.Duration)
+		c := dhook.NewClient(
+			dhook.WithClock(clock),
+			dhook.WithWebhookRateLimit(1, time.Minute),
+			dhook.WithHooks(dhook.Hooks{
+				OnRateLimitWait: func(_ context.Context, limiter string, d time.Duration) {
+					waits[limiter] += d
+				},
+			}),
+		)
+		wh := c.NewWebhook(url)
+		for range 2 {
+			_, err := wh.Execute(dhook.Message{Content: "content"}, nil)
+			assert.NoError(t, err)
+		}
+		assert.Len(t, waits, 1)
+		assert.GreaterOrEqual(t, waits["webhook"], time.Minute)
+	})
+	t.Run("should pass correlation ID to all hooks", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder("POST", url, httpmock.NewErrorResponder(assert.AnError))
+		clock := dhooktest.NewFakeClock(time.Now())
+		got := make(map[string]string)
+		c := dhook.NewClient(
+			dhook.WithClock(clock),
+			dhook.WithWebhookRateLimit(1, time.Minute),
+			dhook.WithHooks(dhook.Hooks{
+				BeforeRequest: func(req *http.Request, _ dhook.Message) {
+					got["BeforeRequest"] = dhook.CorrelationID(req.Context())
+				},
+				AfterResponse: func(ctx context.Context, _ *http.Response, _ time.Duration, _ error) {
+					got["AfterResponse"] = dhook.CorrelationID(ctx)
+				},
+				OnRateLimitWait: func(ctx context.Context, _ string, _ time.Duration) {
+					got["OnRateLimitWait"] = dhook.CorrelationID(ctx)
+				},
+				OnTooManyRequests: func(ctx context.Context, _ dhook.TooManyRequestsError) {
+					got["OnTooManyRequests"] = dhook.CorrelationID(ctx)
+				},
+			}),
+		)
+		wh := c.NewWebhook(url)
+		opt := &dhook.WebhookExecuteOptions{CorrelationID: "abc"}
+		wh.Execute(dhook.Message{Content: "content"}, opt) // fails without response
+		assert.Equal(t, "abc", got["AfterResponse"])
+		clear(got)
+		httpmock.RegisterResponder(
+			"POST",
+			url,
+			httpmock.NewStringResponder(429, "").HeaderSet(http.Header{"Retry-After": []string{"3"}}),
+		)
+		wh.Execute(dhook.Message{Content: "content"}, opt)
+		assert.Equal(t, map[string]string{
+			"BeforeRequest":     "abc",
+			"AfterResponse":     "abc",
+			"OnRateLimitWait":   "abc",
+			"OnTooManyRequests": "abc",
+		}, got)
+	})
+}
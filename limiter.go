@@ -1,6 +1,7 @@
 package dhook
 
 import (
+	"context"
 	"sync"
 	"time"
 )
@@ -8,6 +9,7 @@ import (
 // limiter represents a rate limiter implementing the sliding log algorithm.
 // This type is safe for concurrent use by multiple goroutines.
 type limiter struct {
+	clock  Clock
 	logger Logger
 	max    int
 	name   string
@@ -16,11 +18,15 @@ type limiter struct {
 	mu      sync.Mutex
 	entries []time.Time
 	index   int
+	pacer   pacer
 }
 
+var _ AdjustableRateLimiter = (*limiter)(nil)
+
 // newLimiter returns a new Limiter object.
-func newLimiter(max int, period time.Duration, name string, logger Logger) *limiter {
+func newLimiter(max int, period time.Duration, name string, logger Logger, clock Clock) *limiter {
 	l := limiter{
+		clock:  clock,
 		index:  0,
 		logger: logger,
 		max:    max,
@@ -28,27 +34,125 @@ func newLimiter(max int, period time.Duration, name string, logger Logger) *limi
 		period: period,
 	}
 	l.entries = make([]time.Time, max)
-	before := time.Now().Add(-2 * period)
+	before := clock.Now().Add(-2 * period)
 	for i := range max {
 		l.entries[i] = before
 	}
 	return &l
 }
 
-// wait will register a new event.
+// Wait will register a new event.
 // In case the current tick is exhausted it will block until the tick is reset.
 // The wait duration will be rounded up to the next rate tick (e.g. 100ms if the rate is 10/sec)
-func (l *limiter) wait() {
+//
+// The event is registered only after waiting,
+// so that nothing is registered when ctx is done before.
+func (l *limiter) Wait(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		now := l.clock.Now()
+		at := now
+		if next := l.entries[l.index].Add(l.period); now.Before(next) {
+			at = now.Add(roundUpDuration(next.Sub(now), l.tick()))
+		}
+		paced := l.pacer.earliest(at, l.tick())
+		if !paced.After(now) {
+			l.register(now)
+			l.mu.Unlock()
+			return nil
+		}
+		l.mu.Unlock()
+		if d := at.Sub(now); d > 0 {
+			l.logger.Info("Rate limit exhausted. Waiting for reset", "retryAfter", d, "name", l.name)
+		}
+		if err := l.clock.Sleep(ctx, paced.Sub(now)); err != nil {
+			return err
+		}
+	}
+}
+
+// Reserve registers a new event, but only when it is allowed immediately.
+// Otherwise it returns the duration until the next event is allowed.
+func (l *limiter) Reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.clock.Now()
+	if next := l.next(now); now.Before(next) {
+		return next.Sub(now)
+	}
+	l.register(now)
+	return 0
+}
+
+// State returns a snapshot of the limiter's state.
+func (l *limiter) State() RateLimiterState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.clock.Now()
+	s := RateLimiterState{
+		Limit:  l.max,
+		Period: l.period,
+	}
+	for _, t := range l.entries {
+		if t.Add(l.period).After(now) {
+			s.Used++
+		}
+	}
+	if next := l.next(now); now.Before(next) {
+		s.NextFree = next
+	}
+	return s
+}
+
+// setPacing sets how events are spread within the rate limit.
+func (l *limiter) setPacing(p Pacing) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pacer.pacing = p
+}
+
+// next returns the earliest time at or after now when a new event is allowed.
+// Caller must hold the lock.
+func (l *limiter) next(now time.Time) time.Time {
+	next := l.entries[l.index].Add(l.period)
+	if next.Before(now) {
+		next = now
+	}
+	return l.pacer.earliest(next, l.tick())
+}
+
+// tick returns the duration of one tick, i.e. the period divided by the max events.
+// Caller must hold the lock.
+func (l *limiter) tick() time.Duration {
+	return l.period / time.Duration(l.max)
+}
+
+// SetLimit changes the maximum number of events per period.
+// Registered events are kept, so changing the limit does not allow a new burst.
+func (l *limiter) SetLimit(max int) {
+	if max <= 0 {
+		panic("invalid max")
+	}
 	l.mu.Lock()
 	defer l.mu.Unlock()
-	last := l.entries[l.index]
-	next := last.Add(l.period)
-	if now := time.Now(); now.Before(next) {
-		d := roundUpDuration(next.Sub(now), l.period/time.Duration(l.max))
-		l.logger.Info("Rate limit exhausted. Waiting for reset", "retryAfter", d, "name", l.name)
-		time.Sleep(d)
+	entries := make([]time.Time, 0, max)
+	if n := max - l.max; n > 0 {
+		before := l.clock.Now().Add(-2 * l.period)
+		for range n {
+			entries = append(entries, before)
+		}
 	}
-	l.entries[l.index] = time.Now()
+	entries = append(entries, l.entries[l.index:]...)
+	entries = append(entries, l.entries[:l.index]...)
+	l.entries = entries[len(entries)-max:]
+	l.index = 0
+	l.max = max
+}
+
+// register registers an event at time t. Caller must hold the lock.
+func (l *limiter) register(t time.Time) {
+	l.pacer.register(t, l.tick())
+	l.entries[l.index] = t
 	l.index = l.index + 1
 	if l.index == l.max {
 		l.index = 0
@@ -1,6 +1,7 @@
 package dhook
 
 import (
+	"context"
 	"log/slog"
 	"slices"
 	"sync"
@@ -8,30 +9,33 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/ErikKalkoken/go-dhook/dhooktest"
 )
 
 func TestLimiter(t *testing.T) {
 	t.Run("should allow first 10 calls without delay, but delay the 11st call to successive period", func(t *testing.T) {
 		log := make([]time.Time, 11)
-		l := newLimiter(10, 100*time.Millisecond, "", slog.Default())
-		start := time.Now()
+		c := dhooktest.NewFakeClock(time.Now())
+		l := newLimiter(10, 100*time.Millisecond, "", slog.Default(), c)
+		start := c.Now()
 		for i := 0; i < 11; i++ {
-			l.wait()
-			log[i] = time.Now()
+			l.Wait(context.Background())
+			log[i] = c.Now()
 		}
-		assert.WithinDuration(t, start, log[9], 1*time.Millisecond)
-		assert.WithinDuration(t, start.Add(100*time.Millisecond), log[10], 10*time.Millisecond)
+		assert.Equal(t, start, log[9])
+		assert.Equal(t, start.Add(100*time.Millisecond), log[10])
 	})
 	t.Run("should work concurrently", func(t *testing.T) {
 		log := make([]time.Time, 11)
-		l := newLimiter(10, 100*time.Millisecond, "", slog.Default())
+		l := newLimiter(10, 100*time.Millisecond, "", slog.Default(), realClock{})
 		start := time.Now()
 		var wg sync.WaitGroup
 		for i := 0; i < 11; i++ {
 			wg.Add(1)
 			go func() {
 				defer wg.Done()
-				l.wait()
+				l.Wait(context.Background())
 				log[i] = time.Now()
 			}()
 		}
@@ -44,6 +48,107 @@ func TestLimiter(t *testing.T) {
 	})
 }
 
+func TestLimiter_Wait(t *testing.T) {
+	t.Run("should return error when context is done while waiting", func(t *testing.T) {
+		l := newLimiter(1, time.Minute, "", slog.Default(), realClock{})
+		l.Wait(context.Background())
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		err := l.Wait(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+	t.Run("should not use slot when context is done while waiting", func(t *testing.T) {
+		c := dhooktest.NewFakeClock(time.Now())
+		l := newLimiter(1, time.Minute, "", slog.Default(), c)
+		l.Wait(context.Background())
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := l.Wait(ctx)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 1, l.State().Used)
+		assert.Equal(t, c.Now().Add(time.Minute), l.State().NextFree)
+	})
+	t.Run("should not use paced slot when context is done while waiting", func(t *testing.T) {
+		c := dhooktest.NewFakeClock(time.Now())
+		l := newLimiter(10, time.Minute, "", slog.Default(), c)
+		l.setPacing(Pacing{Mode: PacingSmooth})
+		l.Wait(context.Background())
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		l.Wait(ctx)
+		assert.Equal(t, c.Now().Add(6*time.Second), l.State().NextFree)
+	})
+}
+
+func TestLimiter_Reserve(t *testing.T) {
+	t.Run("should register when slot is free", func(t *testing.T) {
+		l := newLimiter(2, time.Minute, "", slog.Default(), realClock{})
+		assert.Zero(t, l.Reserve())
+		assert.Zero(t, l.Reserve())
+		assert.Equal(t, 2, l.State().Used)
+	})
+	t.Run("should not register and return delay when exhausted", func(t *testing.T) {
+		l := newLimiter(1, time.Minute, "", slog.Default(), realClock{})
+		l.Reserve()
+		d := l.Reserve()
+		assert.InDelta(t, time.Minute, d, float64(time.Second))
+		assert.Equal(t, 1, l.State().Used)
+	})
+}
+
+func TestLimiter_State(t *testing.T) {
+	t.Run("should report unused limiter", func(t *testing.T) {
+		l := newLimiter(3, time.Minute, "", slog.Default(), realClock{})
+		got := l.State()
+		assert.Equal(t, RateLimiterState{Limit: 3, Period: time.Minute}, got)
+	})
+	t.Run("should report exhausted limiter", func(t *testing.T) {
+		l := newLimiter(2, time.Minute, "", slog.Default(), realClock{})
+		l.Reserve()
+		l.Reserve()
+		got := l.State()
+		assert.Equal(t, 2, got.Used)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), got.NextFree, time.Second)
+	})
+}
+
+func TestLimiter_SetLimit(t *testing.T) {
+	t.Run("should keep newest events when lowering limit", func(t *testing.T) {
+		l := newLimiter(5, time.Minute, "", slog.Default(), realClock{})
+		for range 3 {
+			l.Reserve()
+		}
+		l.SetLimit(2)
+		got := l.State()
+		assert.Equal(t, 2, got.Limit)
+		assert.Equal(t, 2, got.Used)
+		assert.NotZero(t, l.Reserve())
+	})
+	t.Run("should add free slots when raising limit", func(t *testing.T) {
+		l := newLimiter(2, time.Minute, "", slog.Default(), realClock{})
+		for range 2 {
+			l.Reserve()
+		}
+		l.SetLimit(3)
+		got := l.State()
+		assert.Equal(t, 3, got.Limit)
+		assert.Equal(t, 2, got.Used)
+		assert.Zero(t, l.Reserve())
+		assert.NotZero(t, l.Reserve())
+	})
+	t.Run("should keep order of events", func(t *testing.T) {
+		l := newLimiter(2, 100*time.Millisecond, "", slog.Default(), realClock{})
+		l.Reserve()
+		time.Sleep(50 * time.Millisecond)
+		l.Reserve()
+		l.Reserve() // slot of first event is not yet free
+		l.SetLimit(3)
+		assert.Zero(t, l.Reserve())
+		d := l.Reserve()
+		assert.InDelta(t, 50*time.Millisecond, d, float64(20*time.Millisecond))
+	})
+}
+
 func TestRoundUpDuration(t *testing.T) {
 	t.Run("should round up small fraction", func(t *testing.T) {
 		x := roundUpDuration(1*time.Second+100*time.Millisecond, time.Second)
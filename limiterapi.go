@@ -1,38 +1,75 @@
 package dhook
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strconv"
+	"sync"
 	"time"
 )
 
 // limiterAPI implements a limiter from the Discord API rate limit
 // as communicated by "X-RateLimit-" response headers.
+// This type is safe for concurrent use by multiple goroutines.
 type limiterAPI struct {
-	rl     rateLimitInfo
+	clock  Clock
 	logger Logger
+
+	mu sync.Mutex
+	rl rateLimitInfo
 }
 
 // wait will wait until a free slot is available if necessary
 // and report whether it has waited.
-func (l *limiterAPI) wait() bool {
-	l.logger.Debug("API rate limit", "info", l.rl)
-	if !l.rl.limitExceeded(time.Now()) {
-		return false
-	}
-	retryAfter := roundUpDuration(time.Until(l.rl.resetAt), time.Second)
+// It returns the context's error when ctx is done while waiting.
+func (l *limiterAPI) wait(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	rl := l.rl
+	l.mu.Unlock()
+	l.logger.Debug("API rate limit", "info", rl)
+	now := l.clock.Now()
+	if !rl.limitExceeded(now) {
+		return false, nil
+	}
+	retryAfter := roundUpDuration(rl.resetAt.Sub(now), time.Second)
 	l.logger.Info("API rate limit exhausted. Waiting for reset", "retryAfter", retryAfter)
-	time.Sleep(retryAfter)
-	return true
+	if err := l.clock.Sleep(ctx, retryAfter); err != nil {
+		return true, err
+	}
+	return true, nil
+}
+
+// delay returns the duration from now until a free slot is available.
+func (l *limiterAPI) delay(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.rl.limitExceeded(now) {
+		return 0
+	}
+	return l.rl.resetAt.Sub(now)
+}
+
+// status returns a snapshot of the current API rate limit.
+func (l *limiterAPI) status() APIRateLimitStatus {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return APIRateLimitStatus{
+		Bucket:    l.rl.bucket,
+		Limit:     l.rl.limit,
+		Remaining: l.rl.remaining,
+		ResetAt:   l.rl.resetAt,
+	}
 }
 
 // updateFromHeader updates the limiter from a header.
 func (l *limiterAPI) updateFromHeader(h http.Header) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
 	if l.rl.remaining > 0 {
 		l.rl.remaining--
 	}
-	rl2, err := newRateLimitInfo(h)
+	rl2, err := newRateLimitInfo(h, l.clock.Now())
 	if err != nil {
 		return err
 	}
@@ -56,10 +93,10 @@ type rateLimitInfo struct {
 	timestamp  time.Time
 }
 
-// newRateLimitInfo returns a new rateLimitInfo from a header.
+// newRateLimitInfo returns a new rateLimitInfo from a header received at time now.
 // Will return an empty rateLimitInfo when the rate limit headers are missing, incomplete.
 // will return an error when the rate limit headers are invalid.
-func newRateLimitInfo(h http.Header) (rateLimitInfo, error) {
+func newRateLimitInfo(h http.Header, now time.Time) (rateLimitInfo, error) {
 	var r rateLimitInfo
 	var err error
 	limit := h.Get("X-RateLimit-Limit")
@@ -103,16 +140,20 @@ func newRateLimitInfo(h http.Header) (rateLimitInfo, error) {
 		return r, wrapErr(err)
 	}
 	r.bucket = bucket
-	r.timestamp = time.Now().UTC()
+	r.timestamp = now.UTC()
 	return r, nil
 }
 
+// String returns a description of the rate limit as received from the Discord API.
+// It reports the reset-after value of the response, so that it does not depend on the current time.
 func (rl rateLimitInfo) String() string {
 	return fmt.Sprintf(
-		"limit:%d remaining:%d reset:%s resetAfter:%f",
+		"limit:%d remaining:%d reset:%s resetAfter:%f bucket:%s",
 		rl.limit,
 		rl.remaining,
-		rl.resetAt, time.Until(rl.resetAt).Seconds(),
+		rl.resetAt,
+		rl.resetAfter,
+		rl.bucket,
 	)
 }
 
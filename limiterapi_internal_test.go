@@ -1,6 +1,7 @@
 package dhook
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -8,6 +9,8 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/ErikKalkoken/go-dhook/dhooktest"
 )
 
 func TestRateLimitInfo_New(t *testing.T) {
@@ -18,7 +21,7 @@ func TestRateLimitInfo_New(t *testing.T) {
 		header.Set("X-RateLimit-Reset", "1470173023")
 		header.Set("X-RateLimit-Reset-After", "1.2")
 		header.Set("X-RateLimit-Bucket", "abcd1234")
-		rl, err := newRateLimitInfo(header)
+		rl, err := newRateLimitInfo(header, time.Now())
 		if assert.NoError(t, err) {
 			assert.Equal(t, 5, rl.limit)
 			assert.Equal(t, 1, rl.remaining)
@@ -29,7 +32,7 @@ func TestRateLimitInfo_New(t *testing.T) {
 	})
 	t.Run("should return empty rate limit if header is incomplete", func(t *testing.T) {
 		header := http.Header{}
-		rl, err := newRateLimitInfo(header)
+		rl, err := newRateLimitInfo(header, time.Now())
 		if assert.NoError(t, err) {
 			assert.True(t, rl.resetAt.IsZero())
 		}
@@ -55,7 +58,7 @@ func TestRateLimitInfo_NewErrorValidation(t *testing.T) {
 			header.Set("X-RateLimit-Reset", tc.reset)
 			header.Set("X-RateLimit-Reset-After", tc.resetAfter)
 			header.Set("X-RateLimit-Bucket", tc.bucket)
-			_, err := newRateLimitInfo(header)
+			_, err := newRateLimitInfo(header, time.Now())
 			if tc.isValid {
 				assert.NoError(t, err)
 			} else {
@@ -85,7 +88,7 @@ func TestRateLimitInfo_ReturnEmptyWhenIncomplete(t *testing.T) {
 			header.Set("X-RateLimit-Reset", tc.reset)
 			header.Set("X-RateLimit-Reset-After", tc.resetAfter)
 			header.Set("X-RateLimit-Bucket", tc.bucket)
-			got, err := newRateLimitInfo(header)
+			got, err := newRateLimitInfo(header, time.Now())
 			if assert.NoError(t, err) {
 				if tc.isEmpty {
 					assert.Empty(t, got)
@@ -98,8 +101,16 @@ func TestRateLimitInfo_ReturnEmptyWhenIncomplete(t *testing.T) {
 }
 
 func TestRateLimitInfo_String(t *testing.T) {
-	x := rateLimitInfo{timestamp: time.Now(), remaining: 0, resetAt: time.Now().Add(5 * time.Second)}
-	assert.NotZero(t, fmt.Sprint(x))
+	rl := rateLimitInfo{
+		limit:      5,
+		remaining:  1,
+		resetAt:    time.Date(2016, 8, 2, 21, 23, 43, 0, time.UTC),
+		resetAfter: 1.2,
+		bucket:     "abcd1234",
+		timestamp:  time.Now(),
+	}
+	want := "limit:5 remaining:1 reset:2016-08-02 21:23:43 +0000 UTC resetAfter:1.200000 bucket:abcd1234"
+	assert.Equal(t, want, rl.String())
 }
 
 func TestRateLimitInfo_LimitExceeded(t *testing.T) {
@@ -170,7 +181,7 @@ func TestLimiterAPI_UpdateFromHeader2(t *testing.T) {
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			l := limiterAPI{rl: tc.current}
+			l := limiterAPI{rl: tc.current, clock: realClock{}}
 			header := http.Header{}
 			header.Set("X-RateLimit-Limit", tc.limit)
 			header.Set("X-RateLimit-Remaining", tc.remaining)
@@ -186,7 +197,7 @@ func TestLimiterAPI_UpdateFromHeader2(t *testing.T) {
 }
 
 func TestLimiterAPI_UpdateFromHeader_Error(t *testing.T) {
-	l := limiterAPI{rl: rateLimitInfo{remaining: 2, resetAt: time.Unix(1470173023, 0).UTC(), bucket: "abcd1234"}}
+	l := limiterAPI{rl: rateLimitInfo{remaining: 2, resetAt: time.Unix(1470173023, 0).UTC(), bucket: "abcd1234"}, clock: realClock{}}
 	header := http.Header{}
 	header.Set("X-RateLimit-Limit", "x")
 	header.Set("X-RateLimit-Remaining", "3")
@@ -200,19 +211,36 @@ func TestLimiterAPI_UpdateFromHeader_Error(t *testing.T) {
 func TestLimiterAPI_Wait(t *testing.T) {
 	t.Run("should not wait if limit not exceeded", func(t *testing.T) {
 		l := limiterAPI{rl: rateLimitInfo{timestamp: time.Now(), remaining: 1}}
+		l.clock = realClock{}
 		l.logger = slog.Default()
-		got := l.wait()
-		assert.False(t, got)
+		got, err := l.wait(context.Background())
+		if assert.NoError(t, err) {
+			assert.False(t, got)
+		}
 	})
 	t.Run("should wait if limit is exceeded", func(t *testing.T) {
-		l := limiterAPI{rl: rateLimitInfo{timestamp: time.Now(), remaining: 0, resetAt: time.Now().Add(200 * time.Millisecond)}}
+		c := dhooktest.NewFakeClock(time.Now())
+		l := limiterAPI{rl: rateLimitInfo{timestamp: c.Now(), remaining: 0, resetAt: c.Now().Add(2 * time.Second)}}
+		l.clock = c
+		l.logger = slog.Default()
+		got, err := l.wait(context.Background())
+		if assert.NoError(t, err) {
+			assert.True(t, got)
+			assert.Equal(t, []time.Duration{2 * time.Second}, c.Sleeps())
+		}
+	})
+	t.Run("should abort waiting when context is done", func(t *testing.T) {
+		l := limiterAPI{rl: rateLimitInfo{timestamp: time.Now(), remaining: 0, resetAt: time.Now().Add(5 * time.Second)}}
+		l.clock = realClock{}
 		l.logger = slog.Default()
-		got := l.wait()
-		assert.True(t, got)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err := l.wait(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
 	})
 }
 
 func TestLimiterAPI_String(t *testing.T) {
 	l := limiterAPI{rl: rateLimitInfo{timestamp: time.Now(), remaining: 0, resetAt: time.Now().Add(200 * time.Millisecond)}}
-	assert.NotEqual(t, "", fmt.Sprint(l))
+	assert.NotEqual(t, "", fmt.Sprint(&l))
 }
@@ -0,0 +1,175 @@
+// Package md provides helpers for formatting text with Discord markdown.
+//
+// The helpers do not escape their input. Use [Escape] for untrusted text, e.g.
+//
+//	md.Bold(md.Escape(userName))
+package md
+
+import (
+	"fmt"
+	"strings"
+)
+
+// zeroWidthSpace is used to break up character sequences, which can not be escaped with a backslash.
+const zeroWidthSpace = "\u200b"
+
+// Bold returns s formatted as bold text.
+func Bold(s string) string {
+	return "**" + s + "**"
+}
+
+// Italic returns s formatted as italic text.
+func Italic(s string) string {
+	return "*" + s + "*"
+}
+
+// Underline returns s formatted as underlined text.
+func Underline(s string) string {
+	return "__" + s + "__"
+}
+
+// Strike returns s formatted as strikethrough text.
+func Strike(s string) string {
+	return "~~" + s + "~~"
+}
+
+// Spoiler returns s formatted as spoiler, which is hidden until clicked.
+func Spoiler(s string) string {
+	return "||" + s + "||"
+}
+
+// InlineCode returns s formatted as inline code.
+// Backticks in s are preserved.
+func InlineCode(s string) string {
+	if !strings.Contains(s, "`") {
+		return "`" + s + "`"
+	}
+	s = strings.ReplaceAll(s, "``", "`"+zeroWidthSpace+"`")
+	if strings.HasPrefix(s, "`") {
+		s = " " + s
+	}
+	if strings.HasSuffix(s, "`") {
+		s += " "
+	}
+	return "``" + s + "``"
+}
+
+// CodeBlock returns text formatted as code block with syntax highlighting for a language, e.g. "go".
+// No syntax highlighting is applied when lang is empty.
+// Code fences in text are broken up, so that they can not end the code block early.
+func CodeBlock(lang, text string) string {
+	text = strings.ReplaceAll(text, "```", "`"+zeroWidthSpace+"``")
+	return "```" + lang + "\n" + text + "\n```"
+}
+
+// Quote returns s formatted as quote. Every line of s is quoted.
+func Quote(s string) string {
+	return prefixLines("> ", s)
+}
+
+// BlockQuote returns s formatted as block quote.
+// A block quote includes all following text of a message.
+func BlockQuote(s string) string {
+	return ">>> " + s
+}
+
+// Header1 returns s formatted as large header.
+func Header1(s string) string {
+	return "# " + s
+}
+
+// Header2 returns s formatted as medium header.
+func Header2(s string) string {
+	return "## " + s
+}
+
+// Header3 returns s formatted as small header.
+func Header3(s string) string {
+	return "### " + s
+}
+
+// Subtext returns s formatted as subtext, which is shown in a small and muted font.
+func Subtext(s string) string {
+	return "-# " + s
+}
+
+// Link returns a masked link, which shows text instead of the URL.
+func Link(text, url string) string {
+	return "[" + text + "](" + url + ")"
+}
+
+// List returns items formatted as unordered list.
+func List(items ...string) string {
+	lines := make([]string, len(items))
+	for i, x := range items {
+		lines[i] = "- " + x
+	}
+	return strings.Join(lines, "\n")
+}
+
+// OrderedList returns items formatted as ordered list starting at 1.
+func OrderedList(items ...string) string {
+	lines := make([]string, len(items))
+	for i, x := range items {
+		lines[i] = fmt.Sprintf("%d. %s", i+1, x)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// prefixLines adds a prefix to every line of s.
+func prefixLines(prefix, s string) string {
+	lines := strings.Split(s, "\n")
+	for i, x := range lines {
+		lines[i] = prefix + x
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Escape returns text with all markdown and mention syntax neutralised,
+// so that it is shown as is, e.g. for forwarding untrusted text.
+//
+// Formatting characters are escaped with a backslash, as well as headers, quotes and lists
+// at the start of a line. Mentions of users, roles and channels, custom emojis and timestamps
+// are escaped by escaping their opening "<" and the mass mentions @everyone and @here
+// are broken up with a zero width space.
+func Escape(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = escapeLine(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// escapeLine escapes a single line of text.
+func escapeLine(line string) string {
+	var b strings.Builder
+	for _, r := range line {
+		switch r {
+		case '\\', '*', '_', '~', '`', '|', '[', ']', '<', '>':
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	s := b.String()
+	trimmed := strings.TrimLeft(s, " \t")
+	s = s[:len(s)-len(trimmed)] + escapeLineStart(trimmed)
+	for _, m := range []string{"@everyone", "@here"} {
+		s = strings.ReplaceAll(s, m, "@"+zeroWidthSpace+m[1:])
+	}
+	return s
+}
+
+// escapeLineStart escapes block syntax at the start of a line, i.e. headers, subtext and lists.
+// Quotes are escaped together with the other formatting characters.
+func escapeLineStart(s string) string {
+	switch {
+	case strings.HasPrefix(s, "#"), strings.HasPrefix(s, "-"), strings.HasPrefix(s, "+"):
+		return `\` + s
+	}
+	digits := strings.TrimLeft(s, "0123456789")
+	if len(digits) < len(s) && strings.HasPrefix(digits, ".") {
+		n := len(s) - len(digits)
+		return s[:n] + `\` + s[n:]
+	}
+	return s
+}
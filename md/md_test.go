@@ -0,0 +1,83 @@
+package md_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ErikKalkoken/go-dhook/md"
+)
+
+func TestFormatting(t *testing.T) {
+	cases := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"bold", md.Bold("alpha"), "**alpha**"},
+		{"italic", md.Italic("alpha"), "*alpha*"},
+		{"underline", md.Underline("alpha"), "__alpha__"},
+		{"strike", md.Strike("alpha"), "~~alpha~~"},
+		{"spoiler", md.Spoiler("alpha"), "||alpha||"},
+		{"inline code", md.InlineCode("alpha"), "`alpha`"},
+		{"inline code with backtick", md.InlineCode("al`pha"), "``al`pha``"},
+		{"inline code with leading backtick", md.InlineCode("`alpha`"), "`` `alpha` ``"},
+		{"inline code with double backticks", md.InlineCode("al``pha"), "``al`\u200b`pha``"},
+		{"code block", md.CodeBlock("go", "x := 1"), "```go\nx := 1\n```"},
+		{"code block without language", md.CodeBlock("", "alpha"), "```\nalpha\n```"},
+		{"code block with fence", md.CodeBlock("", "```alpha```"), "```\n`\u200b``alpha`\u200b``\n```"},
+		{"quote", md.Quote("alpha"), "> alpha"},
+		{"quote with lines", md.Quote("alpha\nbravo"), "> alpha\n> bravo"},
+		{"block quote", md.BlockQuote("alpha\nbravo"), ">>> alpha\nbravo"},
+		{"header 1", md.Header1("alpha"), "# alpha"},
+		{"header 2", md.Header2("alpha"), "## alpha"},
+		{"header 3", md.Header3("alpha"), "### alpha"},
+		{"subtext", md.Subtext("alpha"), "-# alpha"},
+		{"link", md.Link("alpha", "https://www.example.com"), "[alpha](https://www.example.com)"},
+		{"list", md.List("alpha", "bravo"), "- alpha\n- bravo"},
+		{"ordered list", md.OrderedList("alpha", "bravo"), "1. alpha\n2. bravo"},
+		{"empty list", md.List(), ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.got)
+		})
+	}
+}
+
+func TestEscape(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain text", "alpha bravo 1.5 - 2 # 3", "alpha bravo 1.5 - 2 # 3"},
+		{"empty", "", ""},
+		{"bold", "**alpha**", `\*\*alpha\*\*`},
+		{"underline", "__alpha__", `\_\_alpha\_\_`},
+		{"strike", "~~alpha~~", `\~\~alpha\~\~`},
+		{"spoiler", "||alpha||", `\|\|alpha\|\|`},
+		{"inline code", "`alpha`", "\\`alpha\\`"},
+		{"backslash", `alpha\bravo`, `alpha\\bravo`},
+		{"masked link", "[alpha](https://www.example.com)", `\[alpha\](https://www.example.com)`},
+		{"quote", "> alpha", `\> alpha`},
+		{"block quote", ">>> alpha", `\>\>\> alpha`},
+		{"header", "# alpha", `\# alpha`},
+		{"subtext", "-# alpha", `\-# alpha`},
+		{"unordered list", "- alpha\n+ bravo", "\\- alpha\n\\+ bravo"},
+		{"ordered list", "1. alpha\n12. bravo", "1\\. alpha\n12\\. bravo"},
+		{"indented list", "  - alpha", `  \- alpha`},
+		{"user mention", "<@123>", `\<@123\>`},
+		{"role mention", "<@&123>", `\<@&123\>`},
+		{"channel mention", "<#123>", `\<#123\>`},
+		{"custom emoji", "<:alpha:123>", `\<:alpha:123\>`},
+		{"timestamp", "<t:1700000000:R>", `\<t:1700000000:R\>`},
+		{"everyone", "hi @everyone", "hi @\u200beveryone"},
+		{"here", "@here!", "@\u200bhere!"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, md.Escape(tc.in))
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package dhook
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Maximum number of users and roles in allowed mentions.
+const allowedMentionsQuantity = 100
+
+// MentionType represents a type of mentions, which Discord parses from the content of a message.
+type MentionType string
+
+// Mention types
+const (
+	MentionEveryone MentionType = "everyone" // @everyone and @here
+	MentionRoles    MentionType = "roles"
+	MentionUsers    MentionType = "users"
+)
+
+// AllowedMentions controls which mentions in the content of a message notify users and roles.
+// Mentions which are not allowed are still shown, but nobody is notified.
+//
+// Without allowed mentions Discord notifies everybody mentioned in the content,
+// including @everyone and @here. The zero value allows no mentions at all.
+type AllowedMentions struct {
+	Parse []MentionType `json:"parse,omitempty"` // Types of mentions which are all allowed
+	Roles []string      `json:"roles,omitempty"` // IDs of allowed roles. Must be empty when Parse contains MentionRoles.
+	Users []string      `json:"users,omitempty"` // IDs of allowed users. Must be empty when Parse contains MentionUsers.
+}
+
+func (am AllowedMentions) validate(v *checker, path string) {
+	v.maxCount(path+".roles", len(am.Roles), allowedMentionsQuantity)
+	v.maxCount(path+".users", len(am.Users), allowedMentionsQuantity)
+	if len(am.Roles) > 0 && slices.Contains(am.Parse, MentionRoles) {
+		v.add(path+".roles", "must be empty when parsing roles", 0, 0)
+	}
+	if len(am.Users) > 0 && slices.Contains(am.Parse, MentionUsers) {
+		v.add(path+".users", "must be empty when parsing users", 0, 0)
+	}
+}
+
+// TimestampStyle represents the style in which Discord shows a timestamp.
+type TimestampStyle string
+
+// Timestamp styles. The examples are for the en-US locale.
+const (
+	TimestampDefault       TimestampStyle = ""  // Same as TimestampShortDateTime
+	TimestampShortTime     TimestampStyle = "t" // e.g. 4:20 PM
+	TimestampLongTime      TimestampStyle = "T" // e.g. 4:20:30 PM
+	TimestampShortDate     TimestampStyle = "d" // e.g. 04/20/2021
+	TimestampLongDate      TimestampStyle = "D" // e.g. April 20, 2021
+	TimestampShortDateTime TimestampStyle = "f" // e.g. April 20, 2021 4:20 PM
+	TimestampLongDateTime  TimestampStyle = "F" // e.g. Tuesday, April 20, 2021 4:20 PM
+	TimestampRelativeTime  TimestampStyle = "R" // e.g. 2 months ago
+)
+
+// SlashCommand represents a mention of a slash command.
+type SlashCommand struct {
+	Name string // Name of the command, including sub commands, e.g. "tag get"
+	ID   string
+}
+
+// Emoji represents a custom emoji.
+type Emoji struct {
+	Name     string
+	ID       string
+	Animated bool
+}
+
+// FormattedTimestamp represents a timestamp, which Discord shows in the local time of the user.
+type FormattedTimestamp struct {
+	Time  time.Time
+	Style TimestampStyle
+}
+
+var (
+	userMentionRx    = regexp.MustCompile(`<@!?(\d+)>`)
+	roleMentionRx    = regexp.MustCompile(`<@&(\d+)>`)
+	channelMentionRx = regexp.MustCompile(`<#(\d+)>`)
+	slashCommandRx   = regexp.MustCompile(`</([-_\p{L}\p{N}]+(?: [-_\p{L}\p{N}]+){0,2}):(\d+)>`)
+	customEmojiRx    = regexp.MustCompile(`<(a?):(\w{2,32}):(\d+)>`)
+	timestampRx      = regexp.MustCompile(`<t:(-?\d+)(?::([tTdDfFR]))?>`)
+)
+
+// UserMention returns a mention of a user.
+func UserMention(id string) string {
+	return "<@" + id + ">"
+}
+
+// RoleMention returns a mention of a role.
+func RoleMention(id string) string {
+	return "<@&" + id + ">"
+}
+
+// ChannelMention returns a mention of a channel.
+func ChannelMention(id string) string {
+	return "<#" + id + ">"
+}
+
+// SlashCommandMention returns a mention of a slash command,
+// which can include sub commands, e.g. "tag get".
+func SlashCommandMention(name, id string) string {
+	return "</" + name + ":" + id + ">"
+}
+
+// CustomEmoji returns a custom emoji.
+func CustomEmoji(name, id string, animated bool) string {
+	var a string
+	if animated {
+		a = "a"
+	}
+	return fmt.Sprintf("<%s:%s:%s>", a, name, id)
+}
+
+// Timestamp returns a timestamp, which Discord shows in the local time of the user in a style.
+func Timestamp(t time.Time, style TimestampStyle) string {
+	if style == TimestampDefault {
+		return fmt.Sprintf("<t:%d>", t.Unix())
+	}
+	return fmt.Sprintf("<t:%d:%s>", t.Unix(), style)
+}
+
+// ParseUserMentions returns the IDs of all users mentioned in s in order of appearance.
+func ParseUserMentions(s string) []string {
+	return parseIDs(userMentionRx, s)
+}
+
+// ParseRoleMentions returns the IDs of all roles mentioned in s in order of appearance.
+func ParseRoleMentions(s string) []string {
+	return parseIDs(roleMentionRx, s)
+}
+
+// ParseChannelMentions returns the IDs of all channels mentioned in s in order of appearance.
+func ParseChannelMentions(s string) []string {
+	return parseIDs(channelMentionRx, s)
+}
+
+// ParseSlashCommandMentions returns all slash commands mentioned in s in order of appearance.
+func ParseSlashCommandMentions(s string) []SlashCommand {
+	var r []SlashCommand
+	for _, m := range findAll(slashCommandRx, s) {
+		r = append(r, SlashCommand{Name: m[1], ID: m[2]})
+	}
+	return r
+}
+
+// ParseCustomEmojis returns all custom emojis in s in order of appearance.
+func ParseCustomEmojis(s string) []Emoji {
+	var r []Emoji
+	for _, m := range findAll(customEmojiRx, s) {
+		r = append(r, Emoji{Name: m[2], ID: m[3], Animated: m[1] == "a"})
+	}
+	return r
+}
+
+// ParseTimestamps returns all timestamps in s in order of appearance.
+func ParseTimestamps(s string) []FormattedTimestamp {
+	var r []FormattedTimestamp
+	for _, m := range findAll(timestampRx, s) {
+		sec, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil {
+			continue // out of range
+		}
+		r = append(r, FormattedTimestamp{Time: time.Unix(sec, 0), Style: TimestampStyle(m[2])})
+	}
+	return r
+}
+
+// parseIDs returns the IDs matched by the first group of rx.
+func parseIDs(rx *regexp.Regexp, s string) []string {
+	var ids []string
+	for _, m := range findAll(rx, s) {
+		ids = append(ids, m[1])
+	}
+	return ids
+}
+
+// findAll returns the submatches of all matches of rx in s, which are not escaped with a backslash.
+func findAll(rx *regexp.Regexp, s string) [][]string {
+	var r [][]string
+	for _, idx := range rx.FindAllStringSubmatchIndex(s, -1) {
+		if isEscaped(s, idx[0]) {
+			continue
+		}
+		m := make([]string, len(idx)/2)
+		for i := range m {
+			if idx[2*i] >= 0 {
+				m[i] = s[idx[2*i]:idx[2*i+1]]
+			}
+		}
+		r = append(r, m)
+	}
+	return r
+}
+
+// isEscaped reports whether the character at index i of s is escaped,
+// i.e. preceded by an odd number of backslashes.
+func isEscaped(s string, i int) bool {
+	var n int
+	for i > 0 && s[i-1] == '\\' {
+		n++
+		i--
+	}
+	return n%2 == 1
+}
@@ -0,0 +1,125 @@
+package dhook_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ErikKalkoken/go-dhook"
+)
+
+func TestFormatMentions(t *testing.T) {
+	ts := time.Unix(1618953630, 0)
+	cases := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"user", dhook.UserMention("123"), "<@123>"},
+		{"role", dhook.RoleMention("123"), "<@&123>"},
+		{"channel", dhook.ChannelMention("123"), "<#123>"},
+		{"slash command", dhook.SlashCommandMention("tag get", "123"), "</tag get:123>"},
+		{"custom emoji", dhook.CustomEmoji("alpha", "123", false), "<:alpha:123>"},
+		{"animated custom emoji", dhook.CustomEmoji("alpha", "123", true), "<a:alpha:123>"},
+		{"timestamp default", dhook.Timestamp(ts, dhook.TimestampDefault), "<t:1618953630>"},
+		{"timestamp short time", dhook.Timestamp(ts, dhook.TimestampShortTime), "<t:1618953630:t>"},
+		{"timestamp long time", dhook.Timestamp(ts, dhook.TimestampLongTime), "<t:1618953630:T>"},
+		{"timestamp short date", dhook.Timestamp(ts, dhook.TimestampShortDate), "<t:1618953630:d>"},
+		{"timestamp long date", dhook.Timestamp(ts, dhook.TimestampLongDate), "<t:1618953630:D>"},
+		{"timestamp short date time", dhook.Timestamp(ts, dhook.TimestampShortDateTime), "<t:1618953630:f>"},
+		{"timestamp long date time", dhook.Timestamp(ts, dhook.TimestampLongDateTime), "<t:1618953630:F>"},
+		{"timestamp relative time", dhook.Timestamp(ts, dhook.TimestampRelativeTime), "<t:1618953630:R>"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.got)
+		})
+	}
+}
+
+func TestParseMentions(t *testing.T) {
+	t.Run("users", func(t *testing.T) {
+		got := dhook.ParseUserMentions("<@1> <@!2> <@&3> <#4> \\<@5> \\\\<@6>")
+		assert.Equal(t, []string{"1", "2", "6"}, got)
+	})
+	t.Run("roles", func(t *testing.T) {
+		got := dhook.ParseRoleMentions("<@1> <@&2> " + dhook.RoleMention("3"))
+		assert.Equal(t, []string{"2", "3"}, got)
+	})
+	t.Run("channels", func(t *testing.T) {
+		got := dhook.ParseChannelMentions("<@1> <#2> " + dhook.ChannelMention("3"))
+		assert.Equal(t, []string{"2", "3"}, got)
+	})
+	t.Run("slash commands", func(t *testing.T) {
+		got := dhook.ParseSlashCommandMentions("</ping:1> " + dhook.SlashCommandMention("tag get", "2"))
+		assert.Equal(t, []dhook.SlashCommand{{Name: "ping", ID: "1"}, {Name: "tag get", ID: "2"}}, got)
+	})
+	t.Run("custom emojis", func(t *testing.T) {
+		got := dhook.ParseCustomEmojis("<:alpha:1> " + dhook.CustomEmoji("bravo", "2", true))
+		assert.Equal(t, []dhook.Emoji{{Name: "alpha", ID: "1"}, {Name: "bravo", ID: "2", Animated: true}}, got)
+	})
+	t.Run("timestamps", func(t *testing.T) {
+		ts := time.Unix(1618953630, 0)
+		got := dhook.ParseTimestamps(dhook.Timestamp(ts, dhook.TimestampDefault) + " " + dhook.Timestamp(ts, dhook.TimestampRelativeTime))
+		assert.Equal(t, []dhook.FormattedTimestamp{
+			{Time: ts, Style: dhook.TimestampDefault},
+			{Time: ts, Style: dhook.TimestampRelativeTime},
+		}, got)
+	})
+	t.Run("nothing", func(t *testing.T) {
+		assert.Empty(t, dhook.ParseUserMentions("alpha <@bravo>"))
+	})
+}
+
+func TestAllowedMentions_JSON(t *testing.T) {
+	cases := []struct {
+		name string
+		in   dhook.Message
+		want string
+	}{
+		{"default", dhook.Message{Content: "alpha"}, `{"content":"alpha"}`},
+		{"none", dhook.Message{AllowedMentions: &dhook.AllowedMentions{}}, `{"allowed_mentions":{}}`},
+		{
+			"some",
+			dhook.Message{AllowedMentions: &dhook.AllowedMentions{Parse: []dhook.MentionType{dhook.MentionRoles}, Users: []string{"1"}}},
+			`{"allowed_mentions":{"parse":["roles"],"users":["1"]}}`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := json.Marshal(tc.in)
+			if assert.NoError(t, err) {
+				assert.JSONEq(t, tc.want, string(got))
+			}
+		})
+	}
+}
+
+func TestAllowedMentions_Validate(t *testing.T) {
+	many := make([]string, 101)
+	cases := []struct {
+		name string
+		am   dhook.AllowedMentions
+		ok   bool
+	}{
+		{"none", dhook.AllowedMentions{}, true},
+		{"users and roles", dhook.AllowedMentions{Users: []string{"1"}, Roles: []string{"2"}}, true},
+		{"parse all", dhook.AllowedMentions{Parse: []dhook.MentionType{dhook.MentionEveryone, dhook.MentionRoles, dhook.MentionUsers}}, true},
+		{"too many users", dhook.AllowedMentions{Users: many}, false},
+		{"too many roles", dhook.AllowedMentions{Roles: many}, false},
+		{"users when parsing users", dhook.AllowedMentions{Parse: []dhook.MentionType{dhook.MentionUsers}, Users: []string{"1"}}, false},
+		{"roles when parsing roles", dhook.AllowedMentions{Parse: []dhook.MentionType{dhook.MentionRoles}, Roles: []string{"1"}}, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := dhook.Message{Content: "alpha", AllowedMentions: &tc.am}.Validate()
+			if tc.ok {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, dhook.ErrInvalidMessage)
+			}
+		})
+	}
+}
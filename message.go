@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"net/url"
 	"time"
+	"unicode/utf16"
+	"unicode/utf8"
 )
 
 // Color represents a color for Discord embeds.
@@ -71,41 +73,20 @@ var ErrInvalidMessage = errors.New("invalid message")
 
 // Message represents a message that can be send to a Discord webhook.
 type Message struct {
-	AllowedMentions bool    `json:"allowed_mentions,omitempty"`
-	AvatarURL       string  `json:"avatar_url,omitempty"`
-	Content         string  `json:"content,omitempty"`
-	Embeds          []Embed `json:"embeds,omitempty"`
-	Username        string  `json:"username,omitempty"`
+	AllowedMentions *AllowedMentions `json:"allowed_mentions,omitempty"`
+	AvatarURL       string           `json:"avatar_url,omitempty"`
+	Content         string           `json:"content,omitempty"`
+	Embeds          []Embed          `json:"embeds,omitempty"`
+	Username        string           `json:"username,omitempty"`
 }
 
-// Validate checks the message against known Discord limits and requirements
-// It returns an [ErrInvalidMessage] error in case a limit is violated.
+// Validate checks the message against known Discord limits and requirements.
+// It returns a [ValidationError] listing every violation found, which matches [ErrInvalidMessage].
 //
 // Validating messages before sending helps to prevent getting 400 Bad Request response from Discord.
+// Characters are counted as code points. Use [Validator] to count them differently.
 func (m Message) Validate() error {
-	if len(m.Content) == 0 && len(m.Embeds) == 0 {
-		return fmt.Errorf("need to contain content or embeds: %w", ErrInvalidMessage)
-	}
-	if length(m.Content) > contentLength {
-		return fmt.Errorf("content too long: %w", ErrInvalidMessage)
-	}
-	if length(m.Username) > usernameLength {
-		return fmt.Errorf("username too long: %w", ErrInvalidMessage)
-	}
-	if len(m.Embeds) > embedsQuantity {
-		return fmt.Errorf("too many embeds: %w", ErrInvalidMessage)
-	}
-	var totalSize int
-	for _, em := range m.Embeds {
-		if err := em.validate(); err != nil {
-			return err
-		}
-		totalSize += em.size()
-	}
-	if totalSize > embedCombinedLength {
-		return fmt.Errorf("too many characters in combined embeds: %w", ErrInvalidMessage)
-	}
-	return nil
+	return Validator{}.Validate(m)
 }
 
 // Embed represents a Discord Embed.
@@ -124,44 +105,32 @@ type Embed struct {
 	URL         string    `json:"url,omitempty"`
 }
 
-func (em Embed) size() int {
-	x := length(em.Title) + length(em.Description) + length(em.Author.Name) + length(em.Footer.Text)
+// Size returns the number of characters of an embed, which count towards the combined size limit.
+// These are the title, description, author name, footer text and all fields.
+func (em Embed) Size() int {
+	return em.size(CountCodePoints)
+}
+
+func (em Embed) size(c Counting) int {
+	x := c.Len(em.Title) + c.Len(em.Description) + c.Len(em.Author.Name) + c.Len(em.Footer.Text)
 	for _, f := range em.Fields {
-		x += f.size()
+		x += f.size(c)
 	}
 	return x
 }
 
-func (em Embed) validate() error {
-	em.Author.validate()
-	if length(em.Description) > descriptionLength {
-		return fmt.Errorf("embed description too long: %w", ErrInvalidMessage)
-	}
-	em.Footer.validate()
-	if len(em.Fields) > fieldsQuantity {
-		return fmt.Errorf("embed has too many fields: %w", ErrInvalidMessage)
-	}
-	for _, f := range em.Fields {
-		if err := f.validate(); err != nil {
-			return err
-		}
-	}
-	if length(em.Title) > titleLength {
-		return fmt.Errorf("embed title too long: %w", ErrInvalidMessage)
-	}
-	if err := em.Author.validate(); err != nil {
-		return err
-	}
-	if err := em.Footer.validate(); err != nil {
-		return err
-	}
-	if err := em.Image.validate(); err != nil {
-		return err
-	}
-	if err := em.Thumbnail.validate(); err != nil {
-		return err
+func (em Embed) validate(v *checker, path string) {
+	v.maxLength(path+".title", em.Title, titleLength)
+	v.maxLength(path+".description", em.Description, descriptionLength)
+	v.publicURL(path+".url", em.URL)
+	em.Author.validate(v, path+".author")
+	em.Footer.validate(v, path+".footer")
+	em.Image.validate(v, path+".image")
+	em.Thumbnail.validate(v, path+".thumbnail")
+	v.maxCount(path+".fields", len(em.Fields), fieldsQuantity)
+	for i, f := range em.Fields {
+		f.validate(v, fmt.Sprintf("%s.fields[%d]", path, i))
 	}
-	return nil
 }
 
 // Author represents the author in an [Embed].
@@ -171,25 +140,10 @@ type Author struct {
 	IconURL string `json:"icon_url,omitempty"`
 }
 
-func (ea Author) validate() error {
-	if length(ea.Name) > nameLength {
-		return fmt.Errorf("embed author name too long: %w", ErrInvalidMessage)
-	}
-	ok, err := isValidPublicURL(ea.IconURL)
-	if err != nil {
-		return err
-	}
-	if !ok {
-		return fmt.Errorf("embed author icon URL not valid: %w", ErrInvalidMessage)
-	}
-	ok, err = isValidPublicURL(ea.URL)
-	if err != nil {
-		return err
-	}
-	if !ok {
-		return fmt.Errorf("embed author URL not valid: %w", ErrInvalidMessage)
-	}
-	return nil
+func (ea Author) validate(v *checker, path string) {
+	v.maxLength(path+".name", ea.Name, nameLength)
+	v.publicURL(path+".icon_url", ea.IconURL)
+	v.publicURL(path+".url", ea.URL)
 }
 
 // Field represents a field in an [Embed].
@@ -199,21 +153,21 @@ type Field struct {
 	Inline bool   `json:"inline"`
 }
 
-func (ef Field) size() int {
-	return length(ef.Name) + length(ef.Value)
+// Size returns the number of characters of a field, i.e. its name and value.
+func (ef Field) Size() int {
+	return ef.size(CountCodePoints)
+}
+
+func (ef Field) size(c Counting) int {
+	return c.Len(ef.Name) + c.Len(ef.Value)
 }
 
-func (ef Field) validate() error {
+func (ef Field) validate(v *checker, path string) {
 	if ef.Name == "" {
-		return fmt.Errorf("embed field name not defined: %w", ErrInvalidMessage)
+		v.add(path+".name", "not defined", 0, 0)
 	}
-	if length(ef.Name) > nameLength {
-		return fmt.Errorf("embed field name too long: %w", ErrInvalidMessage)
-	}
-	if length(ef.Value) > fieldValueLength {
-		return fmt.Errorf("embed field value too long: %w", ErrInvalidMessage)
-	}
-	return nil
+	v.maxLength(path+".name", ef.Name, nameLength)
+	v.maxLength(path+".value", ef.Value, fieldValueLength)
 }
 
 // Footer represents the footer of an [Embed].
@@ -222,18 +176,9 @@ type Footer struct {
 	IconURL string `json:"icon_url,omitempty"`
 }
 
-func (ef Footer) validate() error {
-	if length(ef.Text) > footerTextLength {
-		return fmt.Errorf("embed footer text too long: %w", ErrInvalidMessage)
-	}
-	ok, err := isValidPublicURL(ef.IconURL)
-	if err != nil {
-		return err
-	}
-	if !ok {
-		return fmt.Errorf("footer icon URL not valid: %w", ErrInvalidMessage)
-	}
-	return nil
+func (ef Footer) validate(v *checker, path string) {
+	v.maxLength(path+".text", ef.Text, footerTextLength)
+	v.publicURL(path+".icon_url", ef.IconURL)
 }
 
 // Image represents the image in an [Embed].
@@ -241,20 +186,61 @@ type Image struct {
 	URL string `json:"url,omitempty"`
 }
 
-func (ei Image) validate() error {
-	ok, err := isValidPublicURL(ei.URL)
-	if err != nil {
-		return err
+func (ei Image) validate(v *checker, path string) {
+	v.publicURL(path+".url", ei.URL)
+}
+
+// Counting represents how the characters of a text are counted towards Discord's limits.
+type Counting uint
+
+// Counting modes
+const (
+	// Counts Unicode code points. This is the default.
+	CountCodePoints Counting = iota
+	// Counts UTF-16 code units, i.e. code points outside the Basic Multilingual Plane,
+	// e.g. most emojis, count as two characters.
+	// This never counts fewer characters than CountCodePoints and is a stricter alternative
+	// for messages, which Discord rejects as too long although they pass validation.
+	CountUTF16
+)
+
+// Len returns the number of characters in s.
+func (c Counting) Len(s string) int {
+	if c == CountCodePoints {
+		return utf8.RuneCountInString(s)
+	}
+	var n int
+	for _, r := range s {
+		n += c.width(r)
+	}
+	return n
+}
+
+// width returns the number of characters a code point counts as.
+func (c Counting) width(r rune) int {
+	if c == CountUTF16 {
+		if n := utf16.RuneLen(r); n > 0 {
+			return n
+		}
 	}
-	if !ok {
-		return fmt.Errorf("embed image URL not valid: %w", ErrInvalidMessage)
+	return 1
+}
+
+// fit returns the number of runes from the start of r, which fit into limit characters.
+func (c Counting) fit(r []rune, limit int) int {
+	var n int
+	for i, x := range r {
+		n += c.width(x)
+		if n > limit {
+			return i
+		}
 	}
-	return nil
+	return len(r)
 }
 
 // length returns the number of runes in a string.
 func length(s string) int {
-	return len([]rune(s))
+	return CountCodePoints.Len(s)
 }
 
 // isValidPublicURL reports whether a raw URL is both a public URL and valid.
@@ -197,6 +197,17 @@ func TestMessage_Validate(t *testing.T) {
 			}}}},
 			false,
 		},
+		// embed URL
+		{
+			"valid embed URL",
+			dhook.Message{Embeds: []dhook.Embed{{Title: "title", URL: validURL}}},
+			true,
+		},
+		{
+			"invalid embed URL",
+			dhook.Message{Embeds: []dhook.Embed{{Title: "title", URL: invalidURL}}},
+			false,
+		},
 		// embed thumbnail
 		{
 			"valid embed image",
@@ -219,7 +230,7 @@ func TestMessage_Validate(t *testing.T) {
 			if tc.ok {
 				assert.NoError(t, err)
 			} else {
-				assert.Error(t, err)
+				assert.ErrorIs(t, err, dhook.ErrInvalidMessage)
 			}
 		})
 	}
@@ -228,3 +239,74 @@ func TestMessage_Validate(t *testing.T) {
 func makeStr(n int) string {
 	return strings.Repeat("x", n)
 }
+
+func TestMessage_ValidateReportsAllErrors(t *testing.T) {
+	m := dhook.Message{
+		Content: makeStr(2001),
+		Embeds: []dhook.Embed{
+			{Description: "description"},
+			{
+				URL:    "//invalid/server/abc",
+				Author: dhook.Author{Name: makeStr(257)},
+				Fields: []dhook.Field{
+					{Name: "name", Value: "value"},
+					{Name: "", Value: "value"},
+					{Name: "name", Value: makeStr(1025)},
+				},
+			},
+		},
+	}
+	err := m.Validate()
+	assert.ErrorIs(t, err, dhook.ErrInvalidMessage)
+	var verr dhook.ValidationError
+	if assert.ErrorAs(t, err, &verr) {
+		assert.Equal(t, []dhook.FieldError{
+			{Path: "content", Reason: "too long", Limit: 2000, Actual: 2001},
+			{Path: "embeds[1].url", Reason: "not a valid public URL"},
+			{Path: "embeds[1].author.name", Reason: "too long", Limit: 256, Actual: 257},
+			{Path: "embeds[1].fields[1].name", Reason: "not defined"},
+			{Path: "embeds[1].fields[2].value", Reason: "too long", Limit: 1024, Actual: 1025},
+		}, verr.Errors)
+	}
+	var ferr dhook.FieldError
+	if assert.ErrorAs(t, err, &ferr) {
+		assert.Equal(t, "content", ferr.Path)
+	}
+	assert.Equal(t, "content: too long: 2001 > 2000\n"+
+		"embeds[1].url: not a valid public URL\n"+
+		"embeds[1].author.name: too long: 257 > 256\n"+
+		"embeds[1].fields[1].name: not defined\n"+
+		"embeds[1].fields[2].value: too long: 1025 > 1024", err.Error())
+}
+
+func TestCounting_Len(t *testing.T) {
+	cases := []struct {
+		name     string
+		in       string
+		counting dhook.Counting
+		want     int
+	}{
+		{"code points", "alpha 😀 boy", dhook.CountCodePoints, 11},
+		{"code points of combining accent", "cafe\u0301", dhook.CountCodePoints, 5},
+		{"utf-16 ascii", "alpha boy", dhook.CountUTF16, 9},
+		{"utf-16 cjk", "你好", dhook.CountUTF16, 2},
+		{"utf-16 emoji", "alpha 😀 boy", dhook.CountUTF16, 12},
+		{"utf-16 zwj sequence", "👨\u200d👩\u200d👧", dhook.CountUTF16, 8},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.counting.Len(tc.in))
+		})
+	}
+}
+
+func TestValidator_Validate(t *testing.T) {
+	t.Run("should count code points by default", func(t *testing.T) {
+		assert.NoError(t, dhook.Message{Content: strings.Repeat("😀", 2000)}.Validate())
+	})
+	t.Run("should count UTF-16 code units", func(t *testing.T) {
+		v := dhook.Validator{Counting: dhook.CountUTF16}
+		assert.NoError(t, v.Validate(dhook.Message{Content: strings.Repeat("😀", 1000)}))
+		assert.ErrorIs(t, v.Validate(dhook.Message{Content: strings.Repeat("😀", 1001)}), dhook.ErrInvalidMessage)
+	})
+}
@@ -0,0 +1,151 @@
+package dhook
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// MetricsHandler returns a HTTP handler, which serves the metrics of a client
+// in the Prometheus text exposition format.
+//
+// The following metrics are provided:
+//   - dhook_messages_sent_total: Number of messages sent successfully
+//   - dhook_messages_failed_total: Number of messages which failed to be sent
+//   - dhook_too_many_requests_total: Number of 429 responses by scope
+//   - dhook_queue_depth: Number of messages currently waiting to be sent
+//   - dhook_requests_total: Number of HTTP requests by webhook and status code
+//   - dhook_request_duration_seconds: Histogram of HTTP request latencies by webhook
+//   - dhook_rate_limit_wait_seconds: Histogram of time spent waiting by limiter (global, api, webhook)
+//   - dhook_rate_limited: Whether the client is currently rate limited globally (0 or 1)
+//   - dhook_webhook_rate_limited: Whether a webhook in use is currently rate limited (0 or 1)
+//
+// Webhooks are identified by their ID. Webhook tokens are never exposed.
+func (c *Client) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		c.writeMetrics(w)
+	})
+}
+
+// writeMetrics writes the metrics of a client in the Prometheus text exposition format.
+func (c *Client) writeMetrics(w io.Writer) error {
+	m := c.stats.metrics()
+	now := c.clock.Now()
+	b := bufio.NewWriter(w)
+	p := metricsPrinter{w: b}
+
+	p.header("dhook_messages_sent_total", "counter", "Number of messages sent successfully.")
+	p.sample("dhook_messages_sent_total", nil, float64(m.sent))
+	p.header("dhook_messages_failed_total", "counter", "Number of messages which failed to be sent.")
+	p.sample("dhook_messages_failed_total", nil, float64(m.failed))
+
+	p.header("dhook_too_many_requests_total", "counter", "Number of 429 responses by scope.")
+	for _, scope := range slices.Sorted(maps.Keys(m.tooManyRequests)) {
+		p.sample("dhook_too_many_requests_total", []string{"scope", scope}, float64(m.tooManyRequests[scope]))
+	}
+
+	p.header("dhook_queue_depth", "gauge", "Number of messages currently waiting to be sent.")
+	p.sample("dhook_queue_depth", nil, float64(m.queueDepth))
+
+	p.header("dhook_requests_total", "counter", "Number of HTTP requests by webhook and status code.")
+	keys := slices.SortedFunc(maps.Keys(m.requests), func(a, b requestKey) int {
+		if x := strings.Compare(a.webhookID, b.webhookID); x != 0 {
+			return x
+		}
+		return a.status - b.status
+	})
+	for _, k := range keys {
+		p.sample("dhook_requests_total", []string{"webhook", k.webhookID, "code", strconv.Itoa(k.status)}, float64(m.requests[k]))
+	}
+
+	p.header("dhook_request_duration_seconds", "histogram", "Latency of HTTP requests by webhook.")
+	for _, id := range slices.Sorted(maps.Keys(m.latency)) {
+		p.histogram("dhook_request_duration_seconds", []string{"webhook", id}, m.latency[id])
+	}
+
+	p.header("dhook_rate_limit_wait_seconds", "histogram", "Time spent waiting for rate limits by limiter.")
+	for _, name := range slices.Sorted(maps.Keys(m.waitHistogram)) {
+		p.histogram("dhook_rate_limit_wait_seconds", []string{"limiter", name}, m.waitHistogram[name])
+	}
+
+	p.header("dhook_rate_limited", "gauge", "Whether the client is currently rate limited globally.")
+	isActive, _ := c.rl.getOrReset(now)
+	p.sample("dhook_rate_limited", nil, boolToFloat(isActive))
+
+	p.header("dhook_webhook_rate_limited", "gauge", "Whether a webhook is currently rate limited.")
+	limited := make(map[string]bool) // webhooks with the same ID are reported together
+	for _, wh := range c.webhookList() {
+		isActive, _ := wh.rl.getOrReset(now)
+		limited[wh.id] = limited[wh.id] || isActive
+	}
+	for _, id := range slices.Sorted(maps.Keys(limited)) {
+		p.sample("dhook_webhook_rate_limited", []string{"webhook", id}, boolToFloat(limited[id]))
+	}
+	if p.err != nil {
+		return p.err
+	}
+	return b.Flush()
+}
+
+// metricsPrinter prints metrics in the Prometheus text exposition format.
+// The first error is kept and all further output is skipped.
+type metricsPrinter struct {
+	w   io.Writer
+	err error
+}
+
+func (p *metricsPrinter) printf(format string, a ...any) {
+	if p.err != nil {
+		return
+	}
+	_, p.err = fmt.Fprintf(p.w, format, a...)
+}
+
+func (p *metricsPrinter) header(name, typ, help string) {
+	p.printf("# HELP %s %s\n", name, help)
+	p.printf("# TYPE %s %s\n", name, typ)
+}
+
+// sample prints a sample. labels are given as pairs of name and value.
+func (p *metricsPrinter) sample(name string, labels []string, v float64) {
+	p.printf("%s%s %s\n", name, formatLabels(labels), strconv.FormatFloat(v, 'g', -1, 64))
+}
+
+func (p *metricsPrinter) histogram(name string, labels []string, h *histogram) {
+	var cumulative uint64
+	for i, bound := range h.bounds {
+		cumulative += h.counts[i]
+		le := strconv.FormatFloat(bound, 'g', -1, 64)
+		p.sample(name+"_bucket", append(slices.Clone(labels), "le", le), float64(cumulative))
+	}
+	p.sample(name+"_bucket", append(slices.Clone(labels), "le", "+Inf"), float64(h.count))
+	p.sample(name+"_sum", labels, h.sum)
+	p.sample(name+"_count", labels, float64(h.count))
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// formatLabels returns labels in the Prometheus format. labels are given as pairs of name and value.
+func formatLabels(labels []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	var s []string
+	for i := 0; i+1 < len(labels); i += 2 {
+		s = append(s, fmt.Sprintf(`%s="%s"`, labels[i], labelValueReplacer.Replace(labels[i+1])))
+	}
+	return "{" + strings.Join(s, ",") + "}"
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,47 @@
+package dhook
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatLabels(t *testing.T) {
+	cases := []struct {
+		name   string
+		labels []string
+		want   string
+	}{
+		{"no labels", nil, ""},
+		{"one label", []string{"a", "1"}, `{a="1"}`},
+		{"two labels", []string{"a", "1", "b", "2"}, `{a="1",b="2"}`},
+		{"escaped value", []string{"a", "x\"y\\z\n"}, `{a="x\"y\\z\n"}`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, formatLabels(tc.labels))
+		})
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	h := newHistogram([]float64{1, 5})
+	h.observe(0.5)
+	h.observe(1)
+	h.observe(3)
+	h.observe(10)
+	assert.Equal(t, []uint64{2, 1}, h.counts)
+	assert.EqualValues(t, 4, h.count)
+	assert.Equal(t, 14.5, h.sum)
+	var b bytes.Buffer
+	p := metricsPrinter{w: &b}
+	p.histogram("x", []string{"a", "1"}, h)
+	want := `x_bucket{a="1",le="1"} 2
+x_bucket{a="1",le="5"} 3
+x_bucket{a="1",le="+Inf"} 4
+x_sum{a="1"} 14.5
+x_count{a="1"} 4
+`
+	assert.Equal(t, want, b.String())
+}
@@ -0,0 +1,62 @@
+package dhook_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ErikKalkoken/go-dhook"
+)
+
+func TestClient_MetricsHandler(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	url1 := "https://discord.com/api/webhooks/123/secret-token"
+	url2 := "https://discord.com/api/webhooks/456/secret-token"
+	httpmock.RegisterResponder("POST", url1, httpmock.NewStringResponder(204, ""))
+	httpmock.RegisterResponder("POST", url2, httpmock.NewStringResponder(429, "").HeaderSet(http.Header{"Retry-After": []string{"60"}}))
+	c := dhook.NewClient()
+	wh1 := c.NewWebhook(url1)
+	wh2 := c.NewWebhook(url2)
+	for range 2 {
+		wh1.Execute(dhook.Message{Content: "content"}, nil)
+	}
+	wh2.Execute(dhook.Message{Content: "content"}, nil)
+	wh3 := c.NewWebhook(url2) // same ID as wh2
+
+	rec := httptest.NewRecorder()
+	c.MetricsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+	got := rec.Body.String()
+	for _, line := range []string{
+		"# TYPE dhook_messages_sent_total counter\n",
+		"dhook_messages_sent_total 2\n",
+		"dhook_messages_failed_total 1\n",
+		`dhook_too_many_requests_total{scope="user"} 1` + "\n",
+		"dhook_queue_depth 0\n",
+		`dhook_requests_total{webhook="123",code="204"} 2` + "\n",
+		`dhook_requests_total{webhook="456",code="429"} 1` + "\n",
+		"# TYPE dhook_request_duration_seconds histogram\n",
+		`dhook_request_duration_seconds_count{webhook="123"} 2` + "\n",
+		`dhook_request_duration_seconds_bucket{webhook="123",le="+Inf"} 2` + "\n",
+		`dhook_rate_limit_wait_seconds_count{limiter="global"} 3` + "\n",
+		`dhook_rate_limit_wait_seconds_count{limiter="api"} 3` + "\n",
+		`dhook_rate_limit_wait_seconds_count{limiter="webhook"} 3` + "\n",
+		"dhook_rate_limited 0\n",
+		`dhook_webhook_rate_limited{webhook="123"} 0` + "\n",
+		`dhook_webhook_rate_limited{webhook="456"} 1` + "\n",
+	} {
+		assert.Contains(t, got, line)
+	}
+	assert.NotContains(t, got, "secret-token")
+	assert.Equal(t, 1, strings.Count(got, `dhook_webhook_rate_limited{webhook="456"}`))
+	runtime.KeepAlive(wh1)
+	runtime.KeepAlive(wh2)
+	runtime.KeepAlive(wh3)
+}
@@ -0,0 +1,73 @@
+package dhook
+
+import "time"
+
+// PacingMode represents a strategy for spacing requests within a rate limit.
+type PacingMode uint
+
+const (
+	// PacingBurst allows all requests of a period to be sent at once.
+	// This is the default.
+	PacingBurst PacingMode = iota
+	// PacingSmooth spaces all requests by a minimum interval.
+	PacingSmooth
+	// PacingBurstThenSmooth allows a burst of requests to be sent at once,
+	// after which requests are spaced by a minimum interval.
+	// The burst becomes available again while the rate limiter is idle.
+	PacingBurstThenSmooth
+)
+
+// Pacing represents the configuration of how requests are spread within a rate limit.
+//
+// Pacing is applied in addition to the rate limit.
+// The zero value is [PacingBurst].
+type Pacing struct {
+	Mode     PacingMode
+	Interval time.Duration // Minimum interval between requests. Zero means the period divided by the limit.
+	Burst    int           // Number of requests allowed at once with [PacingBurstThenSmooth]
+}
+
+// pacer implements pacing with the generic cell rate algorithm (GCRA).
+type pacer struct {
+	pacing Pacing
+	tat    time.Time // theoretical arrival time of the next request
+}
+
+// earliest returns the earliest time at or after t when a request is allowed.
+// defaultInterval is used when no interval is configured.
+func (p *pacer) earliest(t time.Time, defaultInterval time.Duration) time.Time {
+	if p.pacing.Mode == PacingBurst {
+		return t
+	}
+	at := p.tat.Add(-p.tolerance(defaultInterval))
+	if at.After(t) {
+		return at
+	}
+	return t
+}
+
+// register registers a request at time t.
+func (p *pacer) register(t time.Time, defaultInterval time.Duration) {
+	if p.pacing.Mode == PacingBurst {
+		return
+	}
+	if p.tat.Before(t) {
+		p.tat = t
+	}
+	p.tat = p.tat.Add(p.interval(defaultInterval))
+}
+
+func (p *pacer) interval(defaultInterval time.Duration) time.Duration {
+	if p.pacing.Interval > 0 {
+		return p.pacing.Interval
+	}
+	return defaultInterval
+}
+
+// tolerance returns how much earlier than the theoretical arrival time a request is allowed.
+func (p *pacer) tolerance(defaultInterval time.Duration) time.Duration {
+	if p.pacing.Mode != PacingBurstThenSmooth || p.pacing.Burst <= 1 {
+		return 0
+	}
+	return time.Duration(p.pacing.Burst-1) * p.interval(defaultInterval)
+}
@@ -0,0 +1,77 @@
+package dhook
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ErikKalkoken/go-dhook/dhooktest"
+)
+
+func TestLimiter_Pacing(t *testing.T) {
+	// newPacedLimiter returns a new limiter for 10 events per second with a fake clock.
+	newPacedLimiter := func(p Pacing) (*limiter, *dhooktest.FakeClock) {
+		c := dhooktest.NewFakeClock(time.Now())
+		l := newLimiter(10, time.Second, "", slog.Default(), c)
+		l.setPacing(p)
+		return l, c
+	}
+	// offsets returns the offsets of n events from the start when waiting for each.
+	offsets := func(l *limiter, c *dhooktest.FakeClock, n int) []time.Duration {
+		start := c.Now()
+		var got []time.Duration
+		for range n {
+			l.Wait(context.Background())
+			got = append(got, c.Now().Sub(start))
+		}
+		return got
+	}
+	ms := time.Millisecond
+	t.Run("should allow burst by default", func(t *testing.T) {
+		l, c := newPacedLimiter(Pacing{})
+		got := offsets(l, c, 11)
+		want := []time.Duration{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1000 * ms}
+		assert.Equal(t, want, got)
+	})
+	t.Run("should space events evenly with smooth pacing", func(t *testing.T) {
+		l, c := newPacedLimiter(Pacing{Mode: PacingSmooth})
+		got := offsets(l, c, 5)
+		want := []time.Duration{0, 100 * ms, 200 * ms, 300 * ms, 400 * ms}
+		assert.Equal(t, want, got)
+	})
+	t.Run("should space events by custom interval with smooth pacing", func(t *testing.T) {
+		l, c := newPacedLimiter(Pacing{Mode: PacingSmooth, Interval: 250 * ms})
+		got := offsets(l, c, 3)
+		want := []time.Duration{0, 250 * ms, 500 * ms}
+		assert.Equal(t, want, got)
+	})
+	t.Run("should respect rate limit with smooth pacing", func(t *testing.T) {
+		l, c := newPacedLimiter(Pacing{Mode: PacingSmooth, Interval: 10 * ms})
+		got := offsets(l, c, 11)
+		assert.Equal(t, 90*ms, got[9])
+		assert.GreaterOrEqual(t, got[10], 1000*ms)
+	})
+	t.Run("should allow burst then space events", func(t *testing.T) {
+		l, c := newPacedLimiter(Pacing{Mode: PacingBurstThenSmooth, Burst: 3})
+		got := offsets(l, c, 5)
+		want := []time.Duration{0, 0, 0, 100 * ms, 200 * ms}
+		assert.Equal(t, want, got)
+	})
+	t.Run("should allow burst again after idle", func(t *testing.T) {
+		l, c := newPacedLimiter(Pacing{Mode: PacingBurstThenSmooth, Burst: 2, Interval: 50 * ms})
+		offsets(l, c, 3)
+		c.Advance(time.Second)
+		got := offsets(l, c, 3)
+		want := []time.Duration{0, 0, 50 * ms}
+		assert.Equal(t, want, got)
+	})
+	t.Run("should report delay from pacing", func(t *testing.T) {
+		l, _ := newPacedLimiter(Pacing{Mode: PacingSmooth})
+		assert.Zero(t, l.Reserve())
+		assert.Equal(t, 100*ms, l.Reserve())
+		assert.Equal(t, l.clock.Now().Add(100*ms), l.State().NextFree)
+	})
+}
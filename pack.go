@@ -0,0 +1,131 @@
+package dhook
+
+import (
+	"fmt"
+	"time"
+)
+
+// pageFooterReserve is the number of characters reserved for page footers, e.g. " • page 12/34".
+const pageFooterReserve = 20
+
+// EmbedPacker distributes the embeds of a message across a sequence of messages,
+// which each respect Discord's limits for the number of embeds and their combined size.
+//
+// Embeds are packed greedily in order, so that their order is preserved.
+// An embed, which is too large or has too many fields by itself,
+// is split by fields into several embeds. The continuation embeds have a "(cont.)" title.
+// When an embed is still too large, e.g. because it has no fields, its description is truncated.
+//
+// The zero value is ready for use.
+type EmbedPacker struct {
+	// How characters are counted. The default is [CountCodePoints].
+	Counting Counting
+
+	// PageFooters adds "page X/Y" to the footer of the last embed of every message,
+	// when the embeds are distributed across more than one message.
+	PageFooters bool
+}
+
+// Pack returns the sequence of messages for a message.
+// The content is kept with the first message and all messages have the same
+// username, avatar and allowed mentions as the original message.
+//
+// A message with embeds within the limits is returned as is.
+func (p EmbedPacker) Pack(m Message) []Message {
+	if len(m.Embeds) == 0 {
+		return []Message{m}
+	}
+	groups := packEmbeds(m.Embeds, embedCombinedLength, p.Counting)
+	if p.PageFooters && len(groups) > 1 {
+		groups = packEmbeds(m.Embeds, embedCombinedLength-pageFooterReserve, p.Counting)
+	}
+	messages := make([]Message, len(groups))
+	for i, g := range groups {
+		x := m
+		x.Embeds = g
+		if i > 0 {
+			x.Content = ""
+		}
+		if p.PageFooters && len(groups) > 1 {
+			f := &g[len(g)-1].Footer
+			page := fmt.Sprintf("page %d/%d", i+1, len(groups))
+			if f.Text == "" {
+				f.Text = page
+			} else {
+				page = " • " + page
+				if n := footerTextLength - p.Counting.Len(page); p.Counting.Len(f.Text) > n {
+					f.Text = Fitter{Counting: p.Counting}.truncate(f.Text, n)
+				}
+				f.Text += page
+			}
+		}
+		messages[i] = x
+	}
+	return messages
+}
+
+// packEmbeds splits embeds, which are too large, and distributes them in order into groups,
+// which are each within the size limit and the maximum number of embeds.
+// Characters are counted by c.
+func packEmbeds(embeds []Embed, limit int, c Counting) [][]Embed {
+	var groups [][]Embed
+	var current []Embed
+	var size int
+	for _, x := range embeds {
+		for _, em := range splitEmbed(x, limit, c) {
+			n := em.size(c)
+			if len(current) > 0 && (len(current) == embedsQuantity || size+n > limit) {
+				groups = append(groups, current)
+				current, size = nil, 0
+			}
+			current = append(current, em)
+			size += n
+		}
+	}
+	return append(groups, current)
+}
+
+// splitEmbed splits an embed by fields into embeds, which are each within the size limit
+// and the maximum number of fields.
+// The footer and timestamp are moved to the last embed.
+// The description of an embed, which is still too large, is truncated.
+// Characters are counted by c.
+func splitEmbed(em Embed, limit int, c Counting) []Embed {
+	if em.size(c) <= limit && len(em.Fields) <= fieldsQuantity {
+		return []Embed{em}
+	}
+	limit -= c.Len(em.Footer.Text) // reserve room for the footer on the last embed
+	current := em
+	current.Fields = nil
+	current.Footer = Footer{}
+	current.Timestamp = time.Time{}
+	var embeds []Embed
+	for _, f := range em.Fields {
+		if len(current.Fields) > 0 && (len(current.Fields) == fieldsQuantity || current.size(c)+f.size(c) > limit) {
+			embeds = append(embeds, current)
+			current = Embed{Color: em.Color, Title: continuedTitle(em.Title, c)}
+		}
+		current.Fields = append(current.Fields, f)
+	}
+	current.Footer = em.Footer
+	current.Timestamp = em.Timestamp
+	embeds = append(embeds, current)
+	for i := range embeds {
+		x := &embeds[i]
+		if excess := x.size(c) - limit - c.Len(x.Footer.Text); excess > 0 && x.Description != "" {
+			x.Description = Fitter{Counting: c}.truncate(x.Description, max(c.Len(x.Description)-excess, 0))
+		}
+	}
+	return embeds
+}
+
+// continuedTitle returns the title for the continuation of an embed with characters counted by c.
+func continuedTitle(title string, c Counting) string {
+	const suffix = "(cont.)"
+	if title == "" {
+		return suffix
+	}
+	r := []rune(title)
+	r = r[:c.fit(r, titleLength-c.Len(suffix)-1)]
+	return string(r) + " " + suffix
+}
@@ -0,0 +1,155 @@
+package dhook_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ErikKalkoken/go-dhook"
+)
+
+func TestEmbedPacker_Pack(t *testing.T) {
+	makeEmbeds := func(n, size int) []dhook.Embed {
+		var s []dhook.Embed
+		for i := range n {
+			s = append(s, dhook.Embed{Title: fmt.Sprint(i), Description: strings.Repeat("x", size)})
+		}
+		return s
+	}
+	titles := func(m dhook.Message) []string {
+		var s []string
+		for _, em := range m.Embeds {
+			s = append(s, em.Title)
+		}
+		return s
+	}
+	t.Run("should return message within limits as is", func(t *testing.T) {
+		m := dhook.Message{Content: "content", Embeds: makeEmbeds(3, 100)}
+		got := dhook.EmbedPacker{}.Pack(m)
+		assert.Equal(t, []dhook.Message{m}, got)
+	})
+	t.Run("should return message without embeds as is", func(t *testing.T) {
+		m := dhook.Message{Content: "content"}
+		got := dhook.EmbedPacker{}.Pack(m)
+		assert.Equal(t, []dhook.Message{m}, got)
+	})
+	t.Run("should distribute embeds by quantity", func(t *testing.T) {
+		m := dhook.Message{Content: "content", Username: "user", Embeds: makeEmbeds(25, 10)}
+		got := dhook.EmbedPacker{}.Pack(m)
+		if assert.Len(t, got, 3) {
+			assert.Len(t, got[0].Embeds, 10)
+			assert.Len(t, got[1].Embeds, 10)
+			assert.Len(t, got[2].Embeds, 5)
+			assert.Equal(t, "content", got[0].Content)
+			assert.Equal(t, "", got[1].Content)
+			assert.Equal(t, "user", got[2].Username)
+			assert.Equal(t, "10", got[1].Embeds[0].Title)
+		}
+	})
+	t.Run("should distribute embeds by combined size", func(t *testing.T) {
+		m := dhook.Message{Embeds: makeEmbeds(5, 2500)}
+		got := dhook.EmbedPacker{}.Pack(m)
+		if assert.Len(t, got, 3) {
+			assert.Equal(t, []string{"0", "1"}, titles(got[0]))
+			assert.Equal(t, []string{"2", "3"}, titles(got[1]))
+			assert.Equal(t, []string{"4"}, titles(got[2]))
+		}
+		for _, x := range got {
+			assert.NoError(t, x.Validate())
+		}
+	})
+	t.Run("should split embed with too many fields", func(t *testing.T) {
+		ts := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		em := dhook.Embed{Title: "title", Color: dhook.ColorRed, Footer: dhook.Footer{Text: "footer"}, Timestamp: ts}
+		for i := range 30 {
+			em.Fields = append(em.Fields, dhook.Field{Name: fmt.Sprint(i), Value: "value"})
+		}
+		got := dhook.EmbedPacker{}.Pack(dhook.Message{Embeds: []dhook.Embed{em}})
+		if assert.Len(t, got, 1) && assert.Len(t, got[0].Embeds, 2) {
+			first, second := got[0].Embeds[0], got[0].Embeds[1]
+			assert.Equal(t, "title", first.Title)
+			assert.Len(t, first.Fields, 25)
+			assert.Zero(t, first.Footer)
+			assert.Zero(t, first.Timestamp)
+			assert.Equal(t, "title (cont.)", second.Title)
+			assert.Equal(t, dhook.ColorRed, second.Color)
+			assert.Len(t, second.Fields, 5)
+			assert.Equal(t, "25", second.Fields[0].Name)
+			assert.Equal(t, "footer", second.Footer.Text)
+			assert.Equal(t, ts, second.Timestamp)
+			assert.NoError(t, got[0].Validate())
+		}
+	})
+	t.Run("should split embed which is too large", func(t *testing.T) {
+		em := dhook.Embed{Title: "title"}
+		for i := range 8 {
+			em.Fields = append(em.Fields, dhook.Field{Name: fmt.Sprint(i), Value: strings.Repeat("x", 1000)})
+		}
+		got := dhook.EmbedPacker{}.Pack(dhook.Message{Embeds: []dhook.Embed{em}})
+		if assert.Len(t, got, 2) {
+			assert.Equal(t, []string{"title"}, titles(got[0]))
+			assert.Equal(t, []string{"title (cont.)"}, titles(got[1]))
+			assert.Len(t, got[0].Embeds[0].Fields, 5)
+			assert.Len(t, got[1].Embeds[0].Fields, 3)
+		}
+	})
+	t.Run("should truncate description of embed which is too large without fields", func(t *testing.T) {
+		em := dhook.Embed{
+			Title:       strings.Repeat("t", 256),
+			Description: strings.Repeat("d", 4096),
+			Author:      dhook.Author{Name: strings.Repeat("a", 256)},
+			Footer:      dhook.Footer{Text: strings.Repeat("f", 2048)},
+		}
+		got := dhook.EmbedPacker{}.Pack(dhook.Message{Embeds: []dhook.Embed{em}})
+		if assert.Len(t, got, 1) {
+			assert.Equal(t, 6000, got[0].Size())
+			assert.True(t, strings.HasSuffix(got[0].Embeds[0].Description, "…"))
+			assert.NoError(t, got[0].Validate())
+		}
+	})
+	t.Run("should pack with characters counted as set", func(t *testing.T) {
+		em := dhook.Embed{Description: strings.Repeat("😀", 1400)}
+		m := dhook.Message{Embeds: []dhook.Embed{em, em, em}}
+		assert.Equal(t, 1, len(dhook.EmbedPacker{}.Pack(m)))
+		got := dhook.EmbedPacker{Counting: dhook.CountUTF16, PageFooters: true}.Pack(m)
+		assert.Equal(t, 2, len(got))
+		for _, x := range got {
+			assert.NoError(t, dhook.Validator{Counting: dhook.CountUTF16}.Validate(x))
+		}
+	})
+	t.Run("should not reserve room for page footers when not needed", func(t *testing.T) {
+		m := dhook.Message{Embeds: makeEmbeds(2, 2995)}
+		got := dhook.EmbedPacker{PageFooters: true}.Pack(m)
+		assert.Equal(t, []dhook.Message{m}, got)
+	})
+	t.Run("should truncate footer to make room for page", func(t *testing.T) {
+		embeds := makeEmbeds(3, 1000)
+		for i := range embeds {
+			embeds[i].Footer.Text = strings.Repeat("f", 2045)
+		}
+		got := dhook.EmbedPacker{PageFooters: true}.Pack(dhook.Message{Embeds: embeds})
+		if assert.Len(t, got, 3) {
+			for i, x := range got {
+				assert.True(t, strings.HasSuffix(x.Embeds[0].Footer.Text, fmt.Sprintf("… • page %d/3", i+1)))
+				assert.NoError(t, x.Validate())
+			}
+		}
+	})
+	t.Run("can add page footers", func(t *testing.T) {
+		embeds := makeEmbeds(3, 2970)
+		embeds[1].Footer.Text = "footer"
+		m := dhook.Message{Embeds: embeds}
+		got := dhook.EmbedPacker{PageFooters: true}.Pack(m)
+		if assert.Len(t, got, 2) {
+			assert.Equal(t, "footer • page 1/2", got[0].Embeds[1].Footer.Text)
+			assert.Equal(t, "page 2/2", got[1].Embeds[0].Footer.Text)
+			assert.Equal(t, "footer", embeds[1].Footer.Text)
+		}
+		for _, x := range got {
+			assert.NoError(t, x.Validate())
+		}
+	})
+}
@@ -12,15 +12,15 @@ type rateLimited struct {
 	resetAt time.Time
 }
 
-// getOrReset reports whether the rate limit is active and also return the duration until reset.
+// getOrReset reports whether the rate limit is active at time now and also return the duration until reset.
 // Or resets the rate limit if it is expired.
-func (rl *rateLimited) getOrReset() (bool, time.Duration) {
+func (rl *rateLimited) getOrReset(now time.Time) (bool, time.Duration) {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 	if rl.resetAt.IsZero() {
 		return false, 0
 	}
-	d := time.Until(rl.resetAt)
+	d := rl.resetAt.Sub(now)
 	if d < 0 {
 		rl.resetAt = time.Time{}
 		return false, 0
@@ -28,8 +28,18 @@ func (rl *rateLimited) getOrReset() (bool, time.Duration) {
 	return true, d
 }
 
-func (rl *rateLimited) set(retryAfter time.Duration) {
+// set activates the rate limit from time now for the duration retryAfter.
+func (rl *rateLimited) set(now time.Time, retryAfter time.Duration) {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
-	rl.resetAt = time.Now().UTC().Add(retryAfter)
+	rl.resetAt = now.UTC().Add(retryAfter)
+}
+
+// until returns the time when an active rate limit ends or zero if no rate limit is active at time now.
+func (rl *rateLimited) until(now time.Time) time.Time {
+	isActive, d := rl.getOrReset(now)
+	if !isActive {
+		return time.Time{}
+	}
+	return now.UTC().Add(d)
 }
@@ -8,23 +8,29 @@ import (
 )
 
 func TestRateLimited(t *testing.T) {
+	now := time.Now()
 	t.Run("should set", func(t *testing.T) {
 		var rl rateLimited
-		rl.set(5 * time.Minute)
-		ok, d := rl.getOrReset()
+		rl.set(now, 5*time.Minute)
+		ok, d := rl.getOrReset(now)
 		assert.True(t, ok)
-		now := time.Now()
-		assert.WithinDuration(t, now.Add(5*time.Minute), now.Add(d), 1*time.Second)
+		assert.Equal(t, 5*time.Minute, d)
 	})
 	t.Run("should return false when expired", func(t *testing.T) {
 		var rl rateLimited
-		rl.set(-1 * time.Second)
-		ok, _ := rl.getOrReset()
+		rl.set(now, 5*time.Minute)
+		ok, _ := rl.getOrReset(now.Add(5*time.Minute + time.Second))
 		assert.False(t, ok)
 	})
 	t.Run("should report zero-value as not active", func(t *testing.T) {
 		var rl rateLimited
-		ok, _ := rl.getOrReset()
+		ok, _ := rl.getOrReset(now)
 		assert.False(t, ok)
 	})
+	t.Run("should return end of active rate limit", func(t *testing.T) {
+		var rl rateLimited
+		rl.set(now, 5*time.Minute)
+		assert.Equal(t, now.UTC().Add(5*time.Minute), rl.until(now))
+		assert.True(t, rl.until(now.Add(6*time.Minute)).IsZero())
+	})
 }
@@ -0,0 +1,44 @@
+package dhook
+
+import (
+	"context"
+	"time"
+)
+
+// RateLimiter represents a rate limiter, e.g. for the global or a webhook rate limit.
+//
+// Custom rate limiters can be configured for a client with [WithGlobalRateLimiter]
+// and [WithWebhookRateLimiter].
+// Implementations must be safe for concurrent use by multiple goroutines.
+type RateLimiter interface {
+	// Wait blocks until a request is allowed and registers it.
+	// It returns the context's error when the context is done before a request is allowed.
+	Wait(ctx context.Context) error
+
+	// Reserve registers a request and returns zero when the request is allowed immediately.
+	// Otherwise it registers nothing and returns the duration until a request will be allowed.
+	Reserve() time.Duration
+
+	// State returns a snapshot of the current state of the rate limiter.
+	State() RateLimiterState
+}
+
+// RateLimiterState represents a snapshot of the state of a [RateLimiter].
+type RateLimiterState struct {
+	Limit    int           `json:"limit"`     // Maximum number of requests per period
+	Period   time.Duration `json:"period"`    // Duration of a period
+	Used     int           `json:"used"`      // Number of requests registered in the current period
+	NextFree time.Time     `json:"next_free"` // Time when the next request is allowed. Zero when a request is allowed now.
+}
+
+// RateLimiterFactory returns a new [RateLimiter] for the given rate limit.
+// webhookID is the ID of the webhook for webhook rate limiters and empty for the global rate limiter.
+type RateLimiterFactory func(webhookID string, requests int, period time.Duration) RateLimiter
+
+// delay returns the duration from now until the next request is allowed.
+func (s RateLimiterState) delay(now time.Time) time.Duration {
+	if s.NextFree.IsZero() || !s.NextFree.After(now) {
+		return 0
+	}
+	return s.NextFree.Sub(now)
+}
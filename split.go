@@ -0,0 +1,231 @@
+package dhook
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// codeFence is the markdown token for starting and ending a code block.
+const codeFence = "```"
+
+// maxLangLength is the maximum length of the language of a code block.
+const maxLangLength = 20
+
+// protectedRx matches markup, which must not be split, e.g. mentions and custom emojis.
+var protectedRx = regexp.MustCompile(`<(?:@[!&]?\d+|#\d+|a?:\w+:\d+|t:-?\d+(?::[tTdDfFR])?|/[\w -]+:\d+)>`)
+
+// separators are the boundaries for splitting content in order of preference,
+// i.e. paragraphs, lines and words.
+var separators = []string{"\n\n", "\n", " "}
+
+// Splitter splits a message with long content into a sequence of messages,
+// which are each within Discord's content limit.
+//
+// Content is split on paragraph boundaries if possible, else on line and word boundaries.
+// Code blocks are closed at the end of a chunk and reopened with the same language in the next chunk.
+// Mentions, channel links, custom emojis and timestamps are never split.
+//
+// The zero value is ready for use.
+type Splitter struct {
+	// How characters are counted. The default is [CountCodePoints].
+	Counting Counting
+
+	// Maximum length of the content of each message.
+	// The default is Discord's limit of 2000 characters.
+	MaxLength int
+}
+
+// Split returns the sequence of messages for a message.
+// All messages have the same username, avatar and allowed mentions as the original message.
+// Embeds are attached to the last message only.
+//
+// A message with content within the limit is returned as is.
+func (s Splitter) Split(m Message) []Message {
+	limit := s.MaxLength
+	if limit <= 0 {
+		limit = contentLength
+	}
+	chunks := splitContent(m.Content, limit, s.Counting)
+	if len(chunks) < 2 {
+		return []Message{m}
+	}
+	messages := make([]Message, len(chunks))
+	for i, c := range chunks {
+		x := m
+		x.Content = c
+		if i < len(chunks)-1 {
+			x.Embeds = nil
+		}
+		messages[i] = x
+	}
+	return messages
+}
+
+// ExecuteSplit posts a message with long content as a sequence of messages in order.
+// The message is split with the default [Splitter], which counts characters as configured for the client.
+//
+// It returns the responses of the messages posted. When posting a message fails,
+// ExecuteSplit returns the error and does not post the remaining messages.
+func (wh *Webhook) ExecuteSplit(message Message, opt *WebhookExecuteOptions) ([][]byte, error) {
+	if wh.client == nil {
+		return nil, fmt.Errorf("Webhook not initialized: %w", ErrInvalidConfiguration)
+	}
+	var bodies [][]byte
+	messages := Splitter{Counting: wh.client.counting}.Split(message)
+	for i, m := range messages {
+		body, err := wh.Execute(m, opt)
+		if err != nil {
+			return bodies, fmt.Errorf("message %d of %d: %w", i+1, len(messages), err)
+		}
+		bodies = append(bodies, body)
+	}
+	return bodies, nil
+}
+
+// contentMap describes where content may be split.
+// All positions are boundaries between runes, i.e. position i is before rune i.
+type contentMap struct {
+	runes   []rune
+	inFence []bool   // whether a position is inside a code block
+	lang    []string // language of the code block at a position
+	valid   []bool   // whether content may be split at a position
+}
+
+func newContentMap(content string) contentMap {
+	r := []rune(content)
+	n := len(r)
+	m := contentMap{
+		runes:   r,
+		inFence: make([]bool, n+1),
+		lang:    make([]string, n+1),
+		valid:   make([]bool, n+1),
+	}
+	for i := range m.valid {
+		m.valid[i] = true
+	}
+	for _, loc := range protectedRx.FindAllStringIndex(content, -1) {
+		start := utf8.RuneCountInString(content[:loc[0]])
+		end := start + utf8.RuneCountInString(content[loc[0]:loc[1]])
+		for i := start + 1; i < end; i++ {
+			m.valid[i] = false
+		}
+	}
+	var isOpen bool
+	var lang string
+	for i := 0; i < n; {
+		if !m.hasPrefix(i, codeFence) {
+			i++
+			m.inFence[i], m.lang[i] = isOpen, lang
+			continue
+		}
+		end := i + len(codeFence)
+		if isOpen {
+			isOpen, lang = false, ""
+		} else {
+			isOpen = true
+			j := end
+			for j < n && j-end <= maxLangLength && isLangRune(r[j]) {
+				j++
+			}
+			if j > end && j-end <= maxLangLength && j < n && r[j] == '\n' {
+				lang = string(r[end:j])
+				end = j
+			}
+		}
+		for k := i + 1; k <= end; k++ {
+			m.valid[k] = k == end
+			m.inFence[k], m.lang[k] = isOpen, lang
+		}
+		i = end
+	}
+	return m
+}
+
+// isLangRune reports whether r can be part of the language of a code block, e.g. "c++".
+func isLangRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("#+-._", r)
+}
+
+// hasPrefix reports whether the content at position i starts with s.
+func (m contentMap) hasPrefix(i int, s string) bool {
+	r := []rune(s)
+	return i+len(r) <= len(m.runes) && slices.Equal(m.runes[i:i+len(r)], r)
+}
+
+// cut returns the best position for splitting the content between start and the limit hi
+// and the length of the separator at that position.
+func (m contentMap) cut(start, hi int) (int, int) {
+	for _, sep := range separators {
+		for c := hi; c > start; c-- {
+			if m.valid[c] && m.hasPrefix(c, sep) {
+				return c, len(sep)
+			}
+		}
+	}
+	for c := hi; c > start; c-- {
+		if m.valid[c] {
+			return c, 0
+		}
+	}
+	return hi, 0
+}
+
+// splitContent splits content into chunks of at most limit characters as counted by c.
+func splitContent(content string, limit int, c Counting) []string {
+	if c.Len(content) <= limit {
+		return []string{content}
+	}
+	m := newContentMap(content)
+	// Code blocks are only closed and reopened when there is room for it in every chunk.
+	var closeLen int
+	isFenced := m.hasFence() && c.Len(codeFence+"\n\n"+codeFence)+m.maxLangLen(c)+2 <= limit
+	if isFenced {
+		closeLen = c.Len("\n" + codeFence)
+	}
+	var chunks []string
+	var prefix string
+	start, n := 0, len(m.runes)
+	for start < n {
+		if c.Len(prefix)+c.Len(string(m.runes[start:])) <= limit {
+			chunks = append(chunks, prefix+string(m.runes[start:]))
+			return chunks
+		}
+		hi := start + max(c.fit(m.runes[start:], limit-c.Len(prefix)-closeLen), 1)
+		i, sepLen := m.cut(start, hi)
+		chunk := prefix + string(m.runes[start:i])
+		prefix = ""
+		if isFenced && m.inFence[i] {
+			chunk += "\n" + codeFence
+			prefix = codeFence + m.lang[i] + "\n"
+		}
+		chunks = append(chunks, chunk)
+		start = i + sepLen
+		if prefix != "" && m.hasPrefix(start, codeFence) {
+			// The code block ends right here, so it does not need to be reopened.
+			prefix = ""
+			start += len(codeFence)
+			if m.hasPrefix(start, "\n") {
+				start++
+			}
+		}
+	}
+	return chunks
+}
+
+// hasFence reports whether the content contains a code block.
+func (m contentMap) hasFence() bool {
+	return slices.Contains(m.inFence, true)
+}
+
+// maxLangLen returns the length of the longest language of the code blocks in the content.
+func (m contentMap) maxLangLen(c Counting) int {
+	var n int
+	for _, x := range m.lang {
+		n = max(n, c.Len(x))
+	}
+	return n
+}
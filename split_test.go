@@ -0,0 +1,130 @@
+package dhook_test
+
+import (
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ErikKalkoken/go-dhook"
+)
+
+func TestSplitter_Split(t *testing.T) {
+	cases := []struct {
+		name    string
+		max     int
+		content string
+		want    []string
+	}{
+		{"short content", 20, "alpha bravo", []string{"alpha bravo"}},
+		{"empty content", 20, "", []string{""}},
+		{"paragraphs", 20, "alpha bravo\n\ncharlie delta", []string{"alpha bravo", "charlie delta"}},
+		{"lines", 20, "alpha bravo\ncharlie delta", []string{"alpha bravo", "charlie delta"}},
+		{"words", 10, "alpha bravo charlie", []string{"alpha", "bravo", "charlie"}},
+		{"prefer paragraphs over lines", 15, "alpha\nbravo\n\ncharlie", []string{"alpha\nbravo", "charlie"}},
+		{"hard cut", 5, "alphabravo", []string{"alpha", "bravo"}},
+		{"mention", 12, "alpha <@123456789>", []string{"alpha", "<@123456789>"}},
+		{"custom emoji", 8, "ab<:x:123>", []string{"ab", "<:x:123>"}},
+		{
+			"code block",
+			21,
+			"```go\nalpha\nbravo\ncharlie\n```",
+			[]string{"```go\nalpha\nbravo\n```", "```go\ncharlie\n```"},
+		},
+		{
+			"code block without language",
+			14,
+			"```alpha bravo charlie```",
+			[]string{"```alpha\n```", "```\nbravo\n```", "```\ncharlie```"},
+		},
+		{
+			"code block ends at cut",
+			20,
+			"```\nalpha\n```\nbravo charlie delta",
+			[]string{"```\nalpha\n```", "bravo charlie delta"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := dhook.Splitter{MaxLength: tc.max}
+			got := s.Split(dhook.Message{Content: tc.content})
+			var contents []string
+			for _, m := range got {
+				contents = append(contents, m.Content)
+				assert.LessOrEqual(t, len([]rune(m.Content)), tc.max)
+			}
+			assert.Equal(t, tc.want, contents)
+		})
+	}
+	t.Run("should keep embeds on last message", func(t *testing.T) {
+		m := dhook.Message{
+			Content:  "alpha bravo",
+			Embeds:   []dhook.Embed{{Title: "title"}},
+			Username: "user",
+		}
+		got := dhook.Splitter{MaxLength: 6}.Split(m)
+		if assert.Len(t, got, 2) {
+			assert.Equal(t, dhook.Message{Content: "alpha", Username: "user"}, got[0])
+			assert.Equal(t, dhook.Message{Content: "bravo", Username: "user", Embeds: m.Embeds}, got[1])
+		}
+	})
+	t.Run("should split to Discord limit by default", func(t *testing.T) {
+		m := dhook.Message{Content: strings.Repeat("alpha ", 900)}
+		got := dhook.Splitter{}.Split(m)
+		assert.Len(t, got, 3)
+		for _, x := range got {
+			assert.NoError(t, x.Validate())
+		}
+	})
+}
+
+func TestSplitter_SplitRespectsLimit(t *testing.T) {
+	parts := []string{"alpha", " ", "\n", "\n\n", "```", "```go\n", "<@123>", "😀", strings.Repeat("x", 50)}
+	r := rand.New(rand.NewPCG(1, 2))
+	for i := range 1000 {
+		var b strings.Builder
+		for range r.IntN(100) {
+			b.WriteString(parts[r.IntN(len(parts))])
+		}
+		content := b.String()
+		limit := 1 + r.IntN(60)
+		for _, m := range (dhook.Splitter{MaxLength: limit}).Split(dhook.Message{Content: content}) {
+			if !assert.LessOrEqual(t, len([]rune(m.Content)), limit, "case %d: %q", i, content) {
+				return
+			}
+		}
+	}
+	t.Run("should split long code without language within Discord limit", func(t *testing.T) {
+		m := dhook.Message{Content: "```" + strings.Repeat("a", 2500) + "```"}
+		got := dhook.Splitter{}.Split(m)
+		assert.Len(t, got, 2)
+		for _, x := range got {
+			assert.NoError(t, x.Validate())
+		}
+	})
+}
+
+func TestWebhook_ExecuteSplit(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	url := "https://www.example.com/hook"
+	httpmock.RegisterResponder("POST", url, httpmock.NewStringResponder(204, ""))
+	c := dhook.NewClient()
+	wh := c.NewWebhook(url)
+	bodies, err := wh.ExecuteSplit(dhook.Message{Content: strings.Repeat("alpha ", 900)}, nil)
+	if assert.NoError(t, err) {
+		assert.Len(t, bodies, 3)
+		assert.Equal(t, 3, httpmock.GetTotalCallCount())
+	}
+}
+
+func TestSplitter_SplitCountingUTF16(t *testing.T) {
+	m := dhook.Message{Content: strings.Repeat("😀", 1500)}
+	got := dhook.Splitter{Counting: dhook.CountUTF16}.Split(m)
+	if assert.Len(t, got, 2) {
+		assert.Equal(t, strings.Repeat("😀", 1000), got[0].Content)
+		assert.Equal(t, strings.Repeat("😀", 500), got[1].Content)
+	}
+}
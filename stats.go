@@ -0,0 +1,245 @@
+package dhook
+
+import (
+	"expvar"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Histogram buckets in seconds.
+var (
+	latencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+	waitBuckets    = []float64{0.01, 0.1, 0.5, 1, 5, 10, 30, 60, 120}
+)
+
+// Stats represents a snapshot of the statistics of a [Client].
+type Stats struct {
+	MessagesSent    int64                   // Number of messages sent successfully
+	MessagesFailed  int64                   // Number of messages which failed to be sent
+	TooManyRequests map[string]int64        // Number of 429 responses by scope, e.g. "global" or "user"
+	WaitGlobal      time.Duration           // Total time spent waiting for the global rate limit
+	WaitAPI         time.Duration           // Total time spent waiting for API rate limits
+	WaitWebhook     time.Duration           // Total time spent waiting for webhook rate limits
+	QueueDepth      int                     // Number of messages currently waiting to be sent
+	Webhooks        map[string]WebhookStats // Statistics by webhook ID
+}
+
+// WebhookStats represents a snapshot of the statistics of a webhook.
+type WebhookStats struct {
+	MessagesSent   int64         // Number of messages sent successfully
+	MessagesFailed int64         // Number of messages which failed to be sent
+	LastStatus     int           // HTTP status code of the last response. Zero when no response was received.
+	LastLatency    time.Duration // Duration of the last HTTP request
+	LastRequestAt  time.Time     // Time of the last HTTP request
+}
+
+// Limiter names as used in statistics.
+const (
+	limiterNameAPI     = "api"
+	limiterNameGlobal  = "global"
+	limiterNameWebhook = "webhook"
+)
+
+// stats collects the statistics of a client.
+// This type is safe for concurrent use by multiple goroutines.
+type stats struct {
+	mu              sync.Mutex
+	failed          int64
+	latency         map[string]*histogram // by webhook ID
+	queueDepth      int
+	requests        map[requestKey]int64
+	sent            int64
+	tooManyRequests map[string]int64
+	wait            map[string]time.Duration
+	waitHistogram   map[string]*histogram // by limiter name
+	webhooks        map[string]*WebhookStats
+}
+
+// requestKey identifies the requests to a webhook with the same response status code.
+type requestKey struct {
+	webhookID string
+	status    int
+}
+
+func newStats() *stats {
+	s := &stats{
+		latency:         make(map[string]*histogram),
+		requests:        make(map[requestKey]int64),
+		tooManyRequests: make(map[string]int64),
+		wait:            make(map[string]time.Duration),
+		waitHistogram:   make(map[string]*histogram),
+		webhooks:        make(map[string]*WebhookStats),
+	}
+	return s
+}
+
+// addQueued changes the number of messages waiting to be sent by delta.
+func (s *stats) addQueued(delta int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queueDepth += delta
+}
+
+// addWait adds the time spent waiting for a limiter.
+func (s *stats) addWait(limiter string, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.wait[limiter] += max(d, 0)
+	h, ok := s.waitHistogram[limiter]
+	if !ok {
+		h = newHistogram(waitBuckets)
+		s.waitHistogram[limiter] = h
+	}
+	h.observe(d.Seconds())
+}
+
+// recordMessage records the outcome of sending a message to a webhook.
+func (s *stats) recordMessage(webhookID string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	wh := s.webhook(webhookID)
+	if err != nil {
+		s.failed++
+		wh.MessagesFailed++
+	} else {
+		s.sent++
+		wh.MessagesSent++
+	}
+}
+
+// recordResponse records a HTTP response from a webhook.
+// status is zero when no response was received.
+func (s *stats) recordResponse(webhookID string, status int, at time.Time, latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	wh := s.webhook(webhookID)
+	wh.LastStatus = status
+	wh.LastLatency = latency
+	wh.LastRequestAt = at
+	s.requests[requestKey{webhookID, status}]++
+	h, ok := s.latency[webhookID]
+	if !ok {
+		h = newHistogram(latencyBuckets)
+		s.latency[webhookID] = h
+	}
+	h.observe(latency.Seconds())
+}
+
+// recordTooManyRequests records a 429 response for a rate limit scope.
+func (s *stats) recordTooManyRequests(scope string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tooManyRequests[scope]++
+}
+
+// webhook returns the statistics for a webhook. Caller must hold the lock.
+func (s *stats) webhook(id string) *WebhookStats {
+	wh, ok := s.webhooks[id]
+	if !ok {
+		wh = &WebhookStats{}
+		s.webhooks[id] = wh
+	}
+	return wh
+}
+
+// snapshot returns a snapshot of the current statistics.
+func (s *stats) snapshot() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	x := Stats{
+		MessagesFailed:  s.failed,
+		MessagesSent:    s.sent,
+		QueueDepth:      s.queueDepth,
+		TooManyRequests: maps.Clone(s.tooManyRequests),
+		WaitAPI:         s.wait[limiterNameAPI],
+		WaitGlobal:      s.wait[limiterNameGlobal],
+		WaitWebhook:     s.wait[limiterNameWebhook],
+		Webhooks:        make(map[string]WebhookStats, len(s.webhooks)),
+	}
+	for id, wh := range s.webhooks {
+		x.Webhooks[id] = *wh
+	}
+	return x
+}
+
+// metricsSnapshot represents a snapshot of the statistics for metrics.
+type metricsSnapshot struct {
+	failed          int64
+	latency         map[string]*histogram
+	queueDepth      int
+	requests        map[requestKey]int64
+	sent            int64
+	tooManyRequests map[string]int64
+	waitHistogram   map[string]*histogram
+}
+
+// metrics returns a snapshot of the current statistics for metrics.
+func (s *stats) metrics() metricsSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cloneHistograms := func(m map[string]*histogram) map[string]*histogram {
+		x := make(map[string]*histogram, len(m))
+		for k, h := range m {
+			x[k] = h.clone()
+		}
+		return x
+	}
+	x := metricsSnapshot{
+		failed:          s.failed,
+		latency:         cloneHistograms(s.latency),
+		queueDepth:      s.queueDepth,
+		requests:        maps.Clone(s.requests),
+		sent:            s.sent,
+		tooManyRequests: maps.Clone(s.tooManyRequests),
+		waitHistogram:   cloneHistograms(s.waitHistogram),
+	}
+	return x
+}
+
+// histogram represents a histogram of observed values with fixed buckets.
+type histogram struct {
+	bounds []float64 // upper bounds of the buckets in ascending order
+	counts []uint64  // number of observations per bucket, excluding the +Inf bucket
+	count  uint64
+	sum    float64
+}
+
+func newHistogram(bounds []float64) *histogram {
+	h := &histogram{bounds: bounds, counts: make([]uint64, len(bounds))}
+	return h
+}
+
+func (h *histogram) observe(v float64) {
+	for i, b := range h.bounds {
+		if v <= b {
+			h.counts[i]++
+			break
+		}
+	}
+	h.count++
+	h.sum += v
+}
+
+func (h *histogram) clone() *histogram {
+	x := *h
+	x.counts = slices.Clone(h.counts)
+	return &x
+}
+
+// Stats returns a snapshot of the current statistics of a client.
+func (c *Client) Stats() Stats {
+	if c.stats == nil {
+		return Stats{}
+	}
+	return c.stats.snapshot()
+}
+
+// PublishExpvar publishes the statistics of a client as expvar variable with the given name.
+// Like [expvar.Publish] it panics when a variable with the same name already exists.
+func (c *Client) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() any {
+		return c.Stats()
+	}))
+}
@@ -0,0 +1,74 @@
+package dhook
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats(t *testing.T) {
+	t.Run("should report empty stats", func(t *testing.T) {
+		s := newStats()
+		got := s.snapshot()
+		assert.Zero(t, got.MessagesSent)
+		assert.Empty(t, got.TooManyRequests)
+		assert.Empty(t, got.Webhooks)
+	})
+	t.Run("should count messages", func(t *testing.T) {
+		s := newStats()
+		s.recordMessage("1", nil)
+		s.recordMessage("1", nil)
+		s.recordMessage("2", errors.New("failed"))
+		got := s.snapshot()
+		assert.EqualValues(t, 2, got.MessagesSent)
+		assert.EqualValues(t, 1, got.MessagesFailed)
+		assert.EqualValues(t, 2, got.Webhooks["1"].MessagesSent)
+		assert.EqualValues(t, 1, got.Webhooks["2"].MessagesFailed)
+	})
+	t.Run("should record last response", func(t *testing.T) {
+		s := newStats()
+		now := time.Now()
+		s.recordResponse("1", 204, now, time.Second)
+		s.recordResponse("1", 400, now, 2*time.Second)
+		got := s.snapshot()
+		assert.Equal(t, WebhookStats{LastStatus: 400, LastLatency: 2 * time.Second, LastRequestAt: now}, got.Webhooks["1"])
+	})
+	t.Run("should sum wait times by limiter", func(t *testing.T) {
+		s := newStats()
+		s.addWait(limiterNameGlobal, time.Second)
+		s.addWait(limiterNameGlobal, time.Second)
+		s.addWait(limiterNameAPI, 3*time.Second)
+		s.addWait(limiterNameWebhook, 4*time.Second)
+		got := s.snapshot()
+		assert.Equal(t, 2*time.Second, got.WaitGlobal)
+		assert.Equal(t, 3*time.Second, got.WaitAPI)
+		assert.Equal(t, 4*time.Second, got.WaitWebhook)
+	})
+	t.Run("should count 429 responses by scope", func(t *testing.T) {
+		s := newStats()
+		s.recordTooManyRequests("global")
+		s.recordTooManyRequests("user")
+		s.recordTooManyRequests("user")
+		got := s.snapshot()
+		assert.Equal(t, map[string]int64{"global": 1, "user": 2}, got.TooManyRequests)
+	})
+	t.Run("should report queue depth", func(t *testing.T) {
+		s := newStats()
+		s.addQueued(1)
+		s.addQueued(1)
+		s.addQueued(-1)
+		assert.Equal(t, 1, s.snapshot().QueueDepth)
+	})
+	t.Run("should return independent snapshot", func(t *testing.T) {
+		s := newStats()
+		s.recordTooManyRequests("user")
+		s.recordMessage("1", nil)
+		got := s.snapshot()
+		s.recordTooManyRequests("user")
+		s.recordMessage("1", nil)
+		assert.EqualValues(t, 1, got.TooManyRequests["user"])
+		assert.EqualValues(t, 1, got.Webhooks["1"].MessagesSent)
+	})
+}
@@ -0,0 +1,102 @@
+package dhook_test
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ErikKalkoken/go-dhook"
+	"github.com/ErikKalkoken/go-dhook/dhooktest"
+)
+
+func TestClient_Stats(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	url := "https://discord.com/api/webhooks/123/token"
+	t.Run("should report sent messages", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder("POST", url, httpmock.NewStringResponder(204, ""))
+		c := dhook.NewClient()
+		wh := c.NewWebhook(url)
+		for range 2 {
+			wh.Execute(dhook.Message{Content: "content"}, nil)
+		}
+		got := c.Stats()
+		assert.EqualValues(t, 2, got.MessagesSent)
+		assert.EqualValues(t, 0, got.MessagesFailed)
+		assert.Zero(t, got.QueueDepth)
+		assert.EqualValues(t, 2, got.Webhooks["123"].MessagesSent)
+		assert.Equal(t, 204, got.Webhooks["123"].LastStatus)
+	})
+	t.Run("should report failed messages", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder("POST", url, httpmock.NewStringResponder(400, ""))
+		c := dhook.NewClient()
+		wh := c.NewWebhook(url)
+		wh.Execute(dhook.Message{Content: "content"}, nil)
+		wh.Execute(dhook.Message{}, nil)
+		got := c.Stats()
+		assert.EqualValues(t, 0, got.MessagesSent)
+		assert.EqualValues(t, 2, got.MessagesFailed)
+		assert.Equal(t, 400, got.Webhooks["123"].LastStatus)
+	})
+	t.Run("should report 429 responses by scope", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder("POST", url, httpmock.NewStringResponder(429, "").HeaderSet(http.Header{
+			"Retry-After":       []string{"1"},
+			"X-Ratelimit-Scope": []string{"shared"},
+		}))
+		c := dhook.NewClient()
+		wh := c.NewWebhook(url)
+		wh.Execute(dhook.Message{Content: "content"}, nil)
+		got := c.Stats()
+		assert.Equal(t, map[string]int64{"shared": 1}, got.TooManyRequests)
+	})
+	t.Run("should report time spent waiting for webhook rate limit", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder("POST", url, httpmock.NewStringResponder(204, ""))
+		clock := dhooktest.NewFakeClock(time.Now())
+		c := dhook.NewClient(dhook.WithClock(clock), dhook.WithWebhookRateLimit(1, time.Minute))
+		wh := c.NewWebhook(url)
+		for range 2 {
+			wh.Execute(dhook.Message{Content: "content"}, nil)
+		}
+		got := c.Stats()
+		assert.Equal(t, time.Minute, got.WaitWebhook)
+		assert.Zero(t, got.WaitGlobal)
+		assert.Zero(t, got.WaitAPI)
+	})
+	t.Run("should not count dropped messages as failed", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder("POST", url, httpmock.NewStringResponder(204, ""))
+		c := dhook.NewClient(dhook.WithWebhookRateLimit(1, time.Minute))
+		wh := c.NewWebhook(url)
+		for range 2 {
+			wh.TryExecute(dhook.Message{Content: "content"}, nil)
+		}
+		got := c.Stats()
+		assert.EqualValues(t, 1, got.MessagesSent)
+		assert.EqualValues(t, 0, got.MessagesFailed)
+	})
+}
+
+func TestClient_PublishExpvar(t *testing.T) {
+	c := dhook.NewClient()
+	c.PublishExpvar("dhook_test")
+	v := expvar.Get("dhook_test")
+	if assert.NotNil(t, v) {
+		var got dhook.Stats
+		err := json.Unmarshal([]byte(v.String()), &got)
+		if assert.NoError(t, err) {
+			assert.Equal(t, c.Stats(), got)
+		}
+	}
+	assert.Panics(t, func() {
+		c.PublishExpvar("dhook_test")
+	})
+}
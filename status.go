@@ -0,0 +1,80 @@
+package dhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// RateLimitStatus represents a snapshot of the rate limits of a client and its webhooks.
+type RateLimitStatus struct {
+	Global             RateLimiterState         `json:"global"`               // Global rate limit
+	GlobalLimitedUntil time.Time                `json:"global_limited_until"` // End of an active global 429 rate limit. Zero if none.
+	QueueDepth         int                      `json:"queue_depth"`          // Number of messages waiting for the global rate limit
+	Webhooks           []WebhookRateLimitStatus `json:"webhooks"`             // Webhooks in use ordered by ID
+}
+
+// WebhookRateLimitStatus represents a snapshot of the rate limits of a webhook.
+type WebhookRateLimitStatus struct {
+	WebhookID    string             `json:"webhook_id"`
+	API          APIRateLimitStatus `json:"api"`           // API rate limit as reported by Discord
+	Webhook      RateLimiterState   `json:"webhook"`       // Webhook rate limit
+	LimitedUntil time.Time          `json:"limited_until"` // End of an active 429 rate limit. Zero if none.
+	Delay        time.Duration      `json:"delay"`         // Estimated delay for sending a message now
+}
+
+// APIRateLimitStatus represents the API rate limit of a webhook as reported by Discord
+// in the "X-RateLimit-" response headers. It is empty until the first response was received.
+type APIRateLimitStatus struct {
+	Bucket    string    `json:"bucket"`
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	ResetAt   time.Time `json:"reset_at"`
+}
+
+// RateLimitStatus returns a snapshot of the rate limits of a client and all of its webhooks.
+// This can help to find out which rate limit is delaying messages.
+//
+// Webhooks are included as long as they are in use, i.e. referenced by the application.
+// Several webhooks with the same ID are all included.
+func (c *Client) RateLimitStatus() RateLimitStatus {
+	now := c.clock.Now()
+	s := RateLimitStatus{
+		Global:             c.limiterGlobal.State(),
+		GlobalLimitedUntil: c.rl.until(now),
+		QueueDepth:         c.queueGlobal.len(),
+		Webhooks:           make([]WebhookRateLimitStatus, 0),
+	}
+	for _, wh := range c.webhookList() {
+		s.Webhooks = append(s.Webhooks, wh.RateLimitStatus())
+	}
+	return s
+}
+
+// RateLimitStatusHandler returns a HTTP handler, which serves the rate limit status of a client as JSON.
+// See also [Client.RateLimitStatus].
+//
+// Durations are rendered in nanoseconds. Webhook tokens are never exposed.
+func (c *Client) RateLimitStatusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.Encode(c.RateLimitStatus())
+	})
+}
+
+// RateLimitStatus returns a snapshot of the rate limits of a webhook.
+func (wh *Webhook) RateLimitStatus() WebhookRateLimitStatus {
+	if wh.client == nil {
+		return WebhookRateLimitStatus{}
+	}
+	now := wh.client.clock.Now()
+	return WebhookRateLimitStatus{
+		WebhookID:    wh.id,
+		API:          wh.limiterAPI.status(),
+		Webhook:      wh.limiterWebhook.State(),
+		LimitedUntil: wh.rl.until(now),
+		Delay:        wh.EstimateDelay(),
+	}
+}
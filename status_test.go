@@ -0,0 +1,80 @@
+package dhook_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ErikKalkoken/go-dhook"
+	"github.com/ErikKalkoken/go-dhook/dhooktest"
+)
+
+func TestRateLimitStatus(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	url1 := "https://discord.com/api/webhooks/123/secret-token"
+	url2 := "https://discord.com/api/webhooks/456/secret-token"
+	clock := dhooktest.NewFakeClock(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	resetAt := clock.Now().Add(2 * time.Second)
+	httpmock.RegisterResponder("POST", url1, httpmock.NewStringResponder(204, "").HeaderSet(http.Header{
+		"X-RateLimit-Limit":       []string{"5"},
+		"X-RateLimit-Remaining":   []string{"4"},
+		"X-RateLimit-Reset":       []string{strconv.Itoa(int(resetAt.Unix()))},
+		"X-RateLimit-Reset-After": []string{"2"},
+		"X-RateLimit-Bucket":      []string{"abc"},
+	}))
+	httpmock.RegisterResponder("POST", url2, httpmock.NewStringResponder(429, "").HeaderSet(http.Header{
+		"Retry-After": []string{"60"},
+	}))
+	c := dhook.NewClient(dhook.WithClock(clock), dhook.WithWebhookRateLimit(3, time.Minute))
+	wh1 := c.NewWebhook(url1)
+	wh2 := c.NewWebhook(url2)
+	_, err := wh1.Execute(dhook.Message{Content: "content"}, nil)
+	assert.NoError(t, err)
+	wh2.Execute(dhook.Message{Content: "content"}, nil)
+
+	t.Run("should return webhook status", func(t *testing.T) {
+		got := wh1.RateLimitStatus()
+		assert.Equal(t, "123", got.WebhookID)
+		assert.Equal(t, dhook.APIRateLimitStatus{Bucket: "abc", Limit: 5, Remaining: 4, ResetAt: resetAt}, got.API)
+		assert.Equal(t, 3, got.Webhook.Limit)
+		assert.Equal(t, 1, got.Webhook.Used)
+		assert.True(t, got.LimitedUntil.IsZero())
+		assert.Zero(t, got.Delay)
+	})
+	t.Run("should return webhook status when rate limited", func(t *testing.T) {
+		got := wh2.RateLimitStatus()
+		assert.Equal(t, clock.Now().Add(time.Minute), got.LimitedUntil)
+		assert.Equal(t, time.Minute, got.Delay)
+	})
+	t.Run("should return client status", func(t *testing.T) {
+		got := c.RateLimitStatus()
+		assert.Equal(t, 2, got.Global.Used)
+		assert.True(t, got.GlobalLimitedUntil.IsZero())
+		assert.Equal(t, 0, got.QueueDepth)
+		if assert.Len(t, got.Webhooks, 2) {
+			assert.Equal(t, "123", got.Webhooks[0].WebhookID)
+			assert.Equal(t, "456", got.Webhooks[1].WebhookID)
+		}
+	})
+	t.Run("should serve status as JSON", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		c.RateLimitStatusHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/debug/ratelimits", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		assert.NotContains(t, rec.Body.String(), "secret-token")
+		var got dhook.RateLimitStatus
+		if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got)) {
+			assert.Equal(t, c.RateLimitStatus(), got)
+		}
+	})
+	runtime.KeepAlive(wh1)
+	runtime.KeepAlive(wh2)
+}
@@ -0,0 +1,133 @@
+package dhook
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ValidationPolicy represents how a client validates messages before sending them.
+type ValidationPolicy uint
+
+// Validation policies
+const (
+	// Only checks that a message has content or embeds. This is the default.
+	ValidationOff ValidationPolicy = iota
+	// Rejects messages, which fail [Message.Validate].
+	ValidationStrict
+	// Truncates messages to fit Discord's limits with [Message.Fit].
+	ValidationFix
+)
+
+// Validator checks messages against known Discord limits and requirements.
+// See [Message.Validate] for details.
+//
+// The zero value is ready for use.
+type Validator struct {
+	// How characters are counted. The default is [CountCodePoints].
+	Counting Counting
+}
+
+// Validate checks a message against known Discord limits and requirements.
+// It returns a [ValidationError] listing every violation found, which matches [ErrInvalidMessage].
+func (vr Validator) Validate(m Message) error {
+	v := checker{counting: vr.Counting}
+	if len(m.Content) == 0 && len(m.Embeds) == 0 {
+		v.add("content", "need to contain content or embeds", 0, 0)
+	}
+	v.maxLength("content", m.Content, contentLength)
+	v.maxLength("username", m.Username, usernameLength)
+	v.maxCount("embeds", len(m.Embeds), embedsQuantity)
+	for i, em := range m.Embeds {
+		em.validate(&v, fmt.Sprintf("embeds[%d]", i))
+	}
+	if totalSize := m.embedsSize(v.counting); totalSize > embedCombinedLength {
+		v.add("embeds", "too many characters in combined embeds", embedCombinedLength, totalSize)
+	}
+	if m.AllowedMentions != nil {
+		m.AllowedMentions.validate(&v, "allowed_mentions")
+	}
+	return v.err()
+}
+
+// FieldError represents a violation of a Discord limit or requirement by a part of a message.
+// It matches [ErrInvalidMessage].
+type FieldError struct {
+	Path   string // JSON path of the part, e.g. "embeds[1].fields[3].value"
+	Reason string // Description of the violation, e.g. "too long"
+	Limit  int    // Limit of the part or 0 if not applicable
+	Actual int    // Actual size of the part or 0 if not applicable
+}
+
+func (e FieldError) Error() string {
+	if e.Limit == 0 {
+		return fmt.Sprintf("%s: %s", e.Path, e.Reason)
+	}
+	return fmt.Sprintf("%s: %s: %d > %d", e.Path, e.Reason, e.Actual, e.Limit)
+}
+
+func (e FieldError) Is(target error) bool {
+	return target == ErrInvalidMessage
+}
+
+// ValidationError represents all violations found when validating a message.
+// It matches [ErrInvalidMessage] and every [FieldError] can be retrieved with [errors.As].
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e ValidationError) Error() string {
+	return errors.Join(e.Unwrap()...).Error()
+}
+
+func (e ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, x := range e.Errors {
+		errs[i] = x
+	}
+	return errs
+}
+
+// checker collects the violations found when validating a message.
+type checker struct {
+	counting Counting
+	errs     []FieldError
+}
+
+// add adds a violation.
+func (v *checker) add(path, reason string, limit, actual int) {
+	v.errs = append(v.errs, FieldError{Path: path, Reason: reason, Limit: limit, Actual: actual})
+}
+
+// maxLength checks the length of a text against a limit.
+func (v *checker) maxLength(path, s string, limit int) {
+	if n := v.counting.Len(s); n > limit {
+		v.add(path, "too long", limit, n)
+	}
+}
+
+// maxCount checks the number of items against a limit.
+func (v *checker) maxCount(path string, n, limit int) {
+	if n > limit {
+		v.add(path, "too many", limit, n)
+	}
+}
+
+// publicURL checks that a URL is empty or a valid public URL.
+func (v *checker) publicURL(path, rawURL string) {
+	ok, err := isValidPublicURL(rawURL)
+	if err != nil {
+		v.add(path, err.Error(), 0, 0)
+		return
+	}
+	if !ok {
+		v.add(path, "not a valid public URL", 0, 0)
+	}
+}
+
+// err returns the violations as [ValidationError] or nil if there are none.
+func (v *checker) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return ValidationError{Errors: v.errs}
+}
@@ -8,12 +8,15 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
-	"sync"
+	"strings"
 	"time"
 )
 
 const (
+	redacted                        = "xxxxx"
 	retryAfterTooManyRequestDefault = 60 * time.Second
 )
 
@@ -40,6 +43,23 @@ func (e HTTPError) Error() string {
 	return e.Message
 }
 
+// WouldBlockError represents a request, which can not be sent without waiting for a rate limit.
+// It matches [ErrWouldBlock].
+type WouldBlockError struct {
+	RetryAfter time.Duration // Estimated duration until the request can be sent
+}
+
+func (e WouldBlockError) Error() string {
+	return fmt.Sprintf("would block for %s", e.RetryAfter)
+}
+
+func (e WouldBlockError) Is(target error) bool {
+	return target == ErrWouldBlock
+}
+
+// ErrWouldBlock represents a request, which can not be sent without waiting for a rate limit.
+var ErrWouldBlock = errors.New("would block")
+
 // ErrInvalidConfiguration represents an invalid configuration, e.g. a negative HTTP timeout.
 var ErrInvalidConfiguration = errors.New("invalid configuration")
 
@@ -47,75 +67,433 @@ var ErrInvalidConfiguration = errors.New("invalid configuration")
 // Webhooks are safe for concurrent use by multiple goroutines.
 type Webhook struct {
 	client *Client
+	id     string
+	pacing Pacing
 	url    string
+	weight int
 
-	mu             sync.Mutex
+	gate           gate // serializes the requests of the webhook by priority
+	adaptive       *adaptiveRate
 	rl             rateLimited
 	limiterAPI     limiterAPI
-	limiterWebhook *limiter
+	limiterWebhook RateLimiter
+}
+
+// WebhookOption represents an option for configuring a [Webhook].
+type WebhookOption func(*Webhook)
+
+// WithWeight sets the weight of a webhook for the global rate limit.
+//
+// When several webhooks of a client are waiting for the global rate limit,
+// they are served in round-robin order. A webhook with weight n is served up to n times in a row,
+// before the next webhook gets its turn. The default weight is 1.
+func WithWeight(weight int) WebhookOption {
+	if weight <= 0 {
+		panic("weight must be positive")
+	}
+	return func(wh *Webhook) {
+		wh.weight = weight
+	}
+}
+
+// WithPacing sets how requests are spread within the webhook rate limit for a webhook.
+// This overrides the default pacing of the client, see [WithDefaultPacing].
+//
+// For example the following spaces requests evenly:
+//
+//	wh := c.NewWebhook(url, dhook.WithPacing(dhook.Pacing{Mode: dhook.PacingSmooth}))
+func WithPacing(pacing Pacing) WebhookOption {
+	return func(wh *Webhook) {
+		wh.pacing = pacing
+	}
 }
 
+// WebhookExecuteOptions represents options for executing a webhook.
+// The zero value of every option means the default.
 type WebhookExecuteOptions struct {
 	// Waits for server confirmation of message send before response
 	// and returns the created message body.
 	Wait bool
+
+	// Timeout for the HTTP request to Discord. Overrides the HTTP timeout of the client.
+	Timeout time.Duration
+
+	// Maximum duration to wait for rate limits.
+	// When sending the message would require waiting longer, a [WouldBlockError] is returned.
+	MaxRateLimitWait time.Duration
+
+	// Priority of the message when waiting for the global rate limit
+	// and for other messages of the same webhook.
+	// Messages with a higher priority are sent before waiting messages with a lower priority.
+	// The default priority is 0.
+	Priority int
+
+	// Caller supplied ID, which is included in logs and the context of the HTTP request.
+	// It can be retrieved in hooks with [CorrelationID].
+	CorrelationID string
+
+	// Additional headers for the HTTP request. They override the headers of the client
+	// including the User-Agent, but not the Content-Type.
+	Header http.Header
+
+	// Additional query parameters for the HTTP request, e.g. thread_id.
+	Query url.Values
+
+	// Truncates the message to fit Discord's limits instead of failing, see [Message.Fit].
+	// Truncations are logged as warning.
+	Fit bool
 }
 
 // Execute posts a message to the configured webhook and optionally returns the message created by Discord.
 //
 // Options can be provided through opt or opt can be nil for executing without options.
-// Execute will only return a response from Discord (e.g. the message created) when the Wait option is enabled.
+// Execute will only return a response from Discord (e.g. the message created) when the Wait option is enabled
+// or when Discord returned an error.
 //
 // Execute will automatically comply with Discord's rate limits by waiting
 // until there is a free slot to post the message if necessary.
 //
-// Execute will check that a message is not empty, but not do a full validation.
-// A full validation can be performed with [Message.Validate].
+// Execute will check that a message is not empty, but not do a full validation by default.
+// A full validation can be performed with [Message.Validate]
+// or enabled for all messages with [WithValidationPolicy].
 //
 // Common errors returned:
 //   - [HTTPError]: Discord returned HTTP status codes of 400 or above (except 429)
 //   - [TooManyRequestsError]: Discord returned status HTTP status code 429
 //   - [context.DeadlineExceeded]: Timeout is exceeded during the HTTP request to Discord
+//   - [WouldBlockError]: Waiting for a rate limit would exceed the MaxRateLimitWait option
 func (wh *Webhook) Execute(message Message, opt *WebhookExecuteOptions) ([]byte, error) {
+	return wh.execute(message, nil, opt, true)
+}
+
+// ExecuteJSON posts a message encoded as JSON like [Webhook.Execute].
+//
+// The body is sent as is, so that it can contain properties which are not supported by [Message],
+// e.g. components. It is neither validated nor truncated regardless of the validation policy.
+// Hooks receive the body decoded into a [Message] as far as possible.
+// This allows forwarding messages, e.g. in a proxy.
+func (wh *Webhook) ExecuteJSON(body []byte, opt *WebhookExecuteOptions) ([]byte, error) {
+	if !json.Valid(body) {
+		return nil, fmt.Errorf("message is not valid JSON: %w", ErrInvalidMessage)
+	}
+	var message Message
+	json.Unmarshal(body, &message) // only for hooks and logging, so properties which do not fit are ignored
+	return wh.execute(message, body, opt, true)
+}
+
+// TryExecute posts a message to the configured webhook like [Webhook.Execute],
+// but without waiting for a rate limit.
+//
+// When the message can not be sent immediately, TryExecute returns a [WouldBlockError]
+// with the estimated wait time, which matches [ErrWouldBlock].
+// This allows callers to drop or reroute messages instead of blocking.
+func (wh *Webhook) TryExecute(message Message, opt *WebhookExecuteOptions) ([]byte, error) {
+	return wh.execute(message, nil, opt, false)
+}
+
+// EstimateDelay returns how long sending a message to this webhook would have to wait right now
+// to comply with all rate limits.
+// This includes the global rate limit, the API rate limit and the webhook rate limit.
+func (wh *Webhook) EstimateDelay() time.Duration {
+	if wh.client == nil {
+		return 0
+	}
+	now := wh.client.clock.Now()
+	d := max(
+		wh.client.EstimateDelay(),
+		wh.limiterAPI.delay(now),
+		wh.limiterWebhook.State().delay(now),
+	)
+	if isActive, retryAfter := wh.rl.getOrReset(now); isActive {
+		d = max(d, retryAfter)
+	}
+	return d
+}
+
+// RateLimit returns the effective rate limit of a webhook as maximum number of requests per period.
+// This is the learned rate limit when the adaptive webhook rate limit is enabled,
+// see [WithAdaptiveWebhookRateLimit].
+func (wh *Webhook) RateLimit() (int, time.Duration) {
+	if wh.client == nil {
+		return 0, 0
+	}
+	if wh.adaptive != nil {
+		return wh.adaptive.limit(), wh.client.webhookRateLimitPeriod
+	}
+	return wh.client.webhookRateLimitRequests, wh.client.webhookRateLimitPeriod
+}
+
+// execute posts a message to the webhook.
+// When dat is not nil, it is sent as the encoded message without checking the message.
+// It waits for the rate limits when block is true and fails with a [WouldBlockError] otherwise.
+func (wh *Webhook) execute(message Message, dat []byte, opt *WebhookExecuteOptions, block bool) (body []byte, err error) {
 	if wh.client == nil {
 		return nil, fmt.Errorf("Webhook not initialized: %w", ErrInvalidConfiguration)
 	}
-	wh.client.logger.Debug("message", "detail", fmt.Sprintf("%+v", message))
+	if opt == nil {
+		opt = &WebhookExecuteOptions{}
+	}
+	if opt.Timeout < 0 || opt.MaxRateLimitWait < 0 {
+		return nil, fmt.Errorf("negative duration in options: %w", ErrInvalidConfiguration)
+	}
+	defer func() {
+		if errors.Is(err, ErrWouldBlock) {
+			return
+		}
+		wh.client.stats.recordMessage(wh.id, err)
+		if err != nil {
+			wh.client.events.publish(MessageFailed{WebhookID: wh.id, Time: wh.client.clock.Now(), Err: err})
+		} else {
+			wh.client.events.publish(MessageSent{WebhookID: wh.id, Time: wh.client.clock.Now()})
+		}
+	}()
+	wh.client.logger.Debug("message", logArgs(opt, "detail", wh.client.loggedBody(fmt.Sprintf("%+v", message)))...)
+	if dat == nil {
+		message, dat, err = wh.encode(message, opt)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if isActive, retryAfter := wh.client.rl.getOrReset(wh.client.clock.Now()); isActive {
+		return nil, TooManyRequestsError{RetryAfter: retryAfter, Global: true}
+	}
+	ctx := context.Background()
+	if opt.CorrelationID != "" {
+		ctx = context.WithValue(ctx, correlationIDKey{}, opt.CorrelationID)
+	}
+	if err := wh.admit(ctx, opt, block); err != nil {
+		return nil, err
+	}
+	defer wh.gate.unlock()
+	return wh.send(ctx, message, dat, opt)
+}
+
+// encode checks a message according to the validation policy
+// and returns the message to be sent and its JSON encoding.
+func (wh *Webhook) encode(message Message, opt *WebhookExecuteOptions) (Message, []byte, error) {
 	if message.Content == "" && len(message.Embeds) == 0 {
-		return nil, fmt.Errorf("message must have Content or Embed: %w", ErrInvalidMessage)
+		return message, nil, fmt.Errorf("message must have Content or Embed: %w", ErrInvalidMessage)
+	}
+	if opt.Fit || wh.client.validation == ValidationFix {
+		var report []Truncation
+		message, report = Fitter{Counting: wh.client.counting}.Fit(message)
+		if len(report) > 0 {
+			wh.client.logger.Warn("Message truncated to fit", logArgs(opt, "truncations", report)...)
+		}
+	}
+	if wh.client.validation == ValidationStrict {
+		if err := (Validator{Counting: wh.client.counting}).Validate(message); err != nil {
+			return message, nil, err
+		}
 	}
 	dat, err := json.Marshal(message)
-	if err != nil {
-		return nil, err
+	return message, dat, err
+}
+
+// admit waits until the webhook is free and all rate limits allow sending a request.
+// It waits when block is true and fails with a [WouldBlockError] otherwise.
+// On success the caller holds the webhook's lock and must release it.
+func (wh *Webhook) admit(ctx context.Context, opt *WebhookExecuteOptions, block bool) error {
+	wh.client.stats.addQueued(1)
+	defer wh.client.stats.addQueued(-1)
+	if !block {
+		if !wh.gate.tryLock() {
+			return WouldBlockError{RetryAfter: wh.EstimateDelay()}
+		}
+		if err := wh.checkRateLimited(); err != nil {
+			wh.gate.unlock()
+			return err
+		}
+		wh.probe()
+		if err := wh.reserve(); err != nil {
+			wh.gate.unlock()
+			return err
+		}
+		return nil
 	}
-	if isActive, retryAfter := wh.client.rl.getOrReset(); isActive {
-		return nil, TooManyRequestsError{RetryAfter: retryAfter, Global: true}
+	if err := wh.checkRateLimited(); err != nil {
+		return err
+	}
+	if opt.MaxRateLimitWait > 0 {
+		if d := wh.EstimateDelay(); d > opt.MaxRateLimitWait {
+			return WouldBlockError{RetryAfter: d}
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opt.MaxRateLimitWait)
+		defer cancel()
+	}
+	err := wh.wait(ctx, opt.Priority)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return WouldBlockError{RetryAfter: wh.EstimateDelay()}
+	}
+	return err
+}
+
+// checkRateLimited returns a [TooManyRequestsError] while the webhook is rate limited by Discord.
+func (wh *Webhook) checkRateLimited() error {
+	if isActive, retryAfter := wh.rl.getOrReset(wh.client.clock.Now()); isActive {
+		return TooManyRequestsError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// probe lets the adaptive webhook rate limit probe for a higher rate, when enabled.
+func (wh *Webhook) probe() {
+	if wh.adaptive != nil {
+		wh.adaptive.probe(wh.client.clock.Now())
+	}
+}
+
+// wait waits until all rate limits allow sending a request and the webhook is free.
+// On success the caller holds the webhook's lock and must release it.
+//
+// A request waits for the API and webhook rate limits first and is then admitted by the global rate limit,
+// so that several requests of a webhook can wait for the global rate limit at the same time,
+// which makes the weight of a webhook effective.
+// Requests of a webhook are then serialized by priority and the API and webhook rate limits are checked again,
+// because they are updated from the response to the previous request.
+// When a request would have to wait for them again, its global slot is given up and the request starts over,
+// so that a request is always sent right after it was admitted by the global rate limit.
+func (wh *Webhook) wait(ctx context.Context, priority int) error {
+	c := wh.client
+	for {
+		err := wh.waitFor(ctx, limiterNameAPI, wh.limiterAPI.delay, func(ctx context.Context) error {
+			_, err := wh.limiterAPI.wait(ctx)
+			return err
+		})
+		if err == nil {
+			err = wh.waitFor(ctx, limiterNameWebhook, wh.limiterWebhookDelay, func(ctx context.Context) error {
+				if d := wh.limiterWebhookDelay(c.clock.Now()); d > 0 {
+					return c.clock.Sleep(ctx, d)
+				}
+				return nil
+			})
+		}
+		if err == nil {
+			err = wh.waitFor(ctx, limiterNameGlobal, c.queueGlobal.delay, func(ctx context.Context) error {
+				return c.queueGlobal.wait(ctx, wh.url, wh.weight, priority)
+			})
+		}
+		if err != nil {
+			return err
+		}
+		if err := wh.gate.lock(ctx, priority); err != nil {
+			return err
+		}
+		if err := wh.checkRateLimited(); err != nil {
+			wh.gate.unlock()
+			return err
+		}
+		wh.probe()
+		// The webhook slot can not be taken by others while holding the webhook's lock.
+		if wh.limiterAPI.delay(c.clock.Now()) == 0 && wh.limiterWebhook.Reserve() == 0 {
+			return nil
+		}
+		wh.gate.unlock()
+	}
+}
+
+// limiterWebhookDelay returns the duration from now until the webhook rate limit allows sending a request.
+func (wh *Webhook) limiterWebhookDelay(now time.Time) time.Duration {
+	return wh.limiterWebhook.State().delay(now)
+}
+
+// waitFor waits for a rate limit and reports the wait to stats, hooks and subscribers.
+// delay must return the estimated wait time, which is only needed for subscribers.
+func (wh *Webhook) waitFor(
+	ctx context.Context,
+	limiter string,
+	delay func(now time.Time) time.Duration,
+	wait func(ctx context.Context) error,
+) error {
+	c := wh.client
+	start := c.clock.Now()
+	var isWaiting bool
+	if c.events.active() {
+		if d := delay(start); d > 0 {
+			isWaiting = true
+			c.events.publish(RateLimitWaitStarted{WebhookID: wh.id, Time: start, Limiter: limiter, Delay: d})
+		}
 	}
-	wh.mu.Lock()
-	defer wh.mu.Unlock()
-	if isActive, retryAfter := wh.rl.getOrReset(); isActive {
-		return nil, TooManyRequestsError{RetryAfter: retryAfter}
+	err := wait(ctx)
+	now := c.clock.Now()
+	d := now.Sub(start)
+	c.stats.addWait(limiter, d)
+	c.hooks.onRateLimitWait(ctx, limiter, d)
+	if isWaiting {
+		c.events.publish(RateLimitWaitEnded{WebhookID: wh.id, Time: now, Limiter: limiter, Duration: d})
 	}
-	wh.client.limiterGlobal.wait()
-	wh.limiterAPI.wait()
-	wh.limiterWebhook.wait()
+	return err
+}
+
+// reserve registers a request with all rate limits when they allow sending it immediately.
+// Otherwise it returns a [WouldBlockError].
+func (wh *Webhook) reserve() error {
+	now := wh.client.clock.Now()
+	d := max(
+		wh.client.queueGlobal.delay(now),
+		wh.limiterAPI.delay(now),
+		wh.limiterWebhook.State().delay(now),
+	)
+	if d > 0 {
+		return WouldBlockError{RetryAfter: d}
+	}
+	// The global slot is reserved first, because other webhooks can take it since the check above.
+	// The webhook slot can not be taken by others while the caller holds the webhook's lock.
+	if d, ok := wh.client.queueGlobal.reserve(now); !ok {
+		return WouldBlockError{RetryAfter: d}
+	}
+	if d := wh.limiterWebhook.Reserve(); d > 0 {
+		return WouldBlockError{RetryAfter: d}
+	}
+	return nil
+}
 
-	url := wh.url
-	if opt != nil && opt.Wait {
-		url += "?wait=1"
+// send sends a request for posting a message to Discord and returns the response.
+func (wh *Webhook) send(ctx context.Context, message Message, dat []byte, opt *WebhookExecuteOptions) (_ []byte, err error) {
+	rawURL := wh.url
+	q := url.Values{}
+	for k, v := range opt.Query {
+		q[k] = slices.Clone(v)
+	}
+	if opt.Wait {
+		q.Set("wait", "1")
+	}
+	if len(q) > 0 {
+		rawURL += "?" + q.Encode()
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), wh.client.httpTimeout)
+	logURL := redactURL(rawURL)
+	timeout := wh.client.httpTimeout
+	if opt.Timeout > 0 {
+		timeout = opt.Timeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(dat))
+	req, err := http.NewRequestWithContext(ctx, "POST", rawURL, bytes.NewBuffer(dat))
 	if err != nil {
-		return nil, err
+		return nil, redactError(err)
+	}
+	req.Header.Set("User-Agent", wh.client.userAgent)
+	for k, v := range wh.client.headers {
+		req.Header[k] = slices.Clone(v)
+	}
+	for k, v := range opt.Header {
+		req.Header[http.CanonicalHeaderKey(k)] = slices.Clone(v)
 	}
 	req.Header.Set("Content-Type", "application/json")
-	wh.client.logger.Debug("request", "url", url, "body", string(dat))
+	wh.client.hooks.beforeRequest(req, message)
+	wh.client.logger.Debug("request", logArgs(opt, "url", logURL, "body", wh.client.loggedBody(string(dat)))...)
+	start := wh.client.clock.Now()
 	resp, err := wh.client.httpClient.Do(req)
+	latency := wh.client.clock.Now().Sub(start)
+	defer func() {
+		wh.client.hooks.afterResponse(ctx, resp, latency, err)
+	}()
 	if err != nil {
-		return nil, err
+		wh.client.stats.recordResponse(wh.id, 0, start, latency)
+		return nil, redactError(err)
 	}
+	wh.client.stats.recordResponse(wh.id, resp.StatusCode, start, latency)
 	defer resp.Body.Close()
 	if err := wh.limiterAPI.updateFromHeader(resp.Header); err != nil {
 		wh.client.logger.Error("Failed to update API limiter from header", "error", err)
@@ -124,11 +502,17 @@ func (wh *Webhook) Execute(message Message, opt *WebhookExecuteOptions) ([]byte,
 	if err != nil {
 		return nil, err
 	}
-	wh.client.logger.Debug("response", "url", url, "status", resp.Status, "headers", resp.Header, "body", string(body))
+	wh.client.logger.Debug("response", logArgs(
+		opt,
+		"url", logURL,
+		"status", resp.Status,
+		"headers", resp.Header,
+		"body", wh.client.loggedBody(string(body)),
+	)...)
 	if resp.StatusCode >= http.StatusBadRequest {
-		wh.client.logger.Warn("response", "url", url, "status", resp.Status)
+		wh.client.logger.Warn("response", logArgs(opt, "url", logURL, "status", resp.Status)...)
 	} else {
-		wh.client.logger.Info("response", "url", url, "status", resp.Status)
+		wh.client.logger.Info("response", logArgs(opt, "url", logURL, "status", resp.Status)...)
 	}
 	if resp.StatusCode == http.StatusTooManyRequests {
 		var m tooManyRequestsResponse
@@ -145,25 +529,114 @@ func (wh *Webhook) Execute(message Message, opt *WebhookExecuteOptions) ([]byte,
 				retryAfter = time.Duration(x) * time.Second
 			}
 		}
-		wh.rl.set(retryAfter)
+		wh.client.stats.recordTooManyRequests(tooManyRequestsScope(resp.Header, m.Global))
+		now := wh.client.clock.Now()
+		wh.rl.set(now, retryAfter)
 		if m.Global {
-			wh.client.rl.set(retryAfter)
+			wh.client.rl.set(now, retryAfter)
+		} else if wh.adaptive != nil {
+			wh.adaptive.decrease(now)
 		}
-		return body, TooManyRequestsError{
+		err := TooManyRequestsError{
 			RetryAfter: retryAfter, // Value from header is more reliable
 			Global:     m.Global,
 		}
+		wh.client.hooks.onTooManyRequests(ctx, err)
+		wh.client.events.publish(TooManyRequests{
+			WebhookID:  wh.id,
+			Time:       now,
+			RetryAfter: retryAfter,
+			Global:     m.Global,
+		})
+		return body, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		wh.client.events.publish(WebhookGone{WebhookID: wh.id, Time: wh.client.clock.Now()})
 	}
 	if resp.StatusCode >= 400 {
 		err := HTTPError{
 			Status:  resp.StatusCode,
 			Message: resp.Status,
 		}
-		return nil, err
+		return body, err
 	}
 	return body, nil
 }
 
+// String returns a description of the webhook with its token redacted.
+func (wh *Webhook) String() string {
+	return fmt.Sprintf("Webhook{url:%s weight:%d}", redactURL(wh.url), wh.weight)
+}
+
+// GoString returns a description of the webhook with its token redacted.
+// This prevents leaking the token when formatting a webhook with %#v.
+func (wh *Webhook) GoString() string {
+	return wh.String()
+}
+
+// logArgs returns args for logging a request, which includes the correlation ID of the options when set.
+func logArgs(opt *WebhookExecuteOptions, args ...any) []any {
+	if opt.CorrelationID != "" {
+		args = append(args, "correlationID", opt.CorrelationID)
+	}
+	return args
+}
+
+// tooManyRequestsScope returns the scope of a 429 response, e.g. "global".
+func tooManyRequestsScope(h http.Header, global bool) string {
+	if global {
+		return "global"
+	}
+	if s := h.Get("X-RateLimit-Scope"); s != "" {
+		return s
+	}
+	return "user"
+}
+
+// webhookID returns the ID of a webhook from its URL or an empty string if the URL has no ID.
+// Webhook URLs have the form: https://discord.com/api/webhooks/{id}/{token}
+func webhookID(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i, p := range parts {
+		if p == "webhooks" && i+1 < len(parts) {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
+
+// redactURL returns a webhook URL with its token replaced, so it can be logged safely.
+// Webhook URLs have the form: https://discord.com/api/webhooks/{id}/{token}
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return redacted
+	}
+	parts := strings.Split(u.Path, "/")
+	for i, p := range parts {
+		if p == "webhooks" && i+2 < len(parts) && parts[i+2] != "" {
+			parts[i+2] = redacted
+			break
+		}
+	}
+	u.Path = strings.Join(parts, "/")
+	u.RawPath = ""
+	return u.String()
+}
+
+// redactError returns an error with the token of the webhook URL in an [*url.Error] redacted.
+func redactError(err error) error {
+	var ue *url.Error
+	if errors.As(err, &ue) {
+		ue.URL = redactURL(ue.URL)
+	}
+	return err
+}
+
 type tooManyRequestsResponse struct {
 	Message    string  `json:"message,omitempty"`
 	RetryAfter float64 `json:"retry_after,omitempty"`
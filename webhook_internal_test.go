@@ -1,27 +1,390 @@
 package dhook
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/ErikKalkoken/go-dhook/dhooktest"
 )
 
 func TestWebhook(t *testing.T) {
 	t.Run("should abort when rateLimitExceeded and not yet reset", func(t *testing.T) {
 		c := NewClient()
 		wh := c.NewWebhook("url")
-		wh.rl.set(60 * time.Second)
+		wh.rl.set(time.Now(), 60*time.Second)
 		_, err := wh.Execute(Message{Content: "content"}, nil)
 		err2, _ := err.(TooManyRequestsError)
 		assert.False(t, err2.Global)
 	})
 	t.Run("should abort when rateLimitExceeded and not yet reset", func(t *testing.T) {
 		c := NewClient()
-		c.rl.set(60 * time.Second)
+		c.rl.set(time.Now(), 60*time.Second)
 		wh := c.NewWebhook("url")
 		_, err := wh.Execute(Message{Content: "content"}, nil)
 		err2, _ := err.(TooManyRequestsError)
 		assert.True(t, err2.Global)
 	})
 }
+
+func TestWebhook_EstimateDelay(t *testing.T) {
+	t.Run("should report delay of active rate limit", func(t *testing.T) {
+		c := NewClient()
+		wh := c.NewWebhook("url")
+		wh.rl.set(time.Now(), 60*time.Second)
+		assert.InDelta(t, 60*time.Second, wh.EstimateDelay(), float64(time.Second))
+	})
+	t.Run("should report delay of API rate limit", func(t *testing.T) {
+		c := NewClient()
+		wh := c.NewWebhook("url")
+		wh.limiterAPI.rl = rateLimitInfo{timestamp: time.Now(), remaining: 0, resetAt: time.Now().Add(30 * time.Second)}
+		assert.InDelta(t, 30*time.Second, wh.EstimateDelay(), float64(time.Second))
+	})
+	t.Run("should report delay of active global rate limit", func(t *testing.T) {
+		c := NewClient()
+		c.rl.set(time.Now(), 60*time.Second)
+		wh := c.NewWebhook("url")
+		assert.InDelta(t, 60*time.Second, wh.EstimateDelay(), float64(time.Second))
+	})
+}
+
+func TestWebhook_TryExecute(t *testing.T) {
+	t.Run("should return error when API rate limit would block", func(t *testing.T) {
+		c := NewClient()
+		wh := c.NewWebhook("url")
+		wh.limiterAPI.rl = rateLimitInfo{timestamp: time.Now(), remaining: 0, resetAt: time.Now().Add(30 * time.Second)}
+		_, err := wh.TryExecute(Message{Content: "content"}, nil)
+		assert.ErrorIs(t, err, ErrWouldBlock)
+	})
+	t.Run("should return error when webhook is busy", func(t *testing.T) {
+		c := NewClient()
+		wh := c.NewWebhook("url")
+		wh.gate.tryLock()
+		defer wh.gate.unlock()
+		_, err := wh.TryExecute(Message{Content: "content"}, nil)
+		assert.ErrorIs(t, err, ErrWouldBlock)
+	})
+	t.Run("should not use webhook slot when global slot was taken in the meantime", func(t *testing.T) {
+		c := NewClient(WithGlobalRateLimiter(func(_ string, _ int, _ time.Duration) RateLimiter {
+			return &tickLimiter{} // reports a free slot, but never has one
+		}))
+		wh := c.NewWebhook("url")
+		wh.gate.tryLock()
+		defer wh.gate.unlock()
+		err := wh.reserve()
+		assert.ErrorIs(t, err, ErrWouldBlock)
+		assert.Zero(t, wh.limiterWebhook.State().Used)
+	})
+}
+
+func TestTooManyRequestsScope(t *testing.T) {
+	cases := []struct {
+		name, header string
+		global       bool
+		want         string
+	}{
+		{"global", "", true, "global"},
+		{"global with header", "shared", true, "global"},
+		{"from header", "shared", false, "shared"},
+		{"default", "", false, "user"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := http.Header{}
+			if tc.header != "" {
+				h.Set("X-RateLimit-Scope", tc.header)
+			}
+			assert.Equal(t, tc.want, tooManyRequestsScope(h, tc.global))
+		})
+	}
+}
+
+func TestWebhookID(t *testing.T) {
+	cases := []struct {
+		url, want string
+	}{
+		{"https://discord.com/api/webhooks/123/token", "123"},
+		{"https://discord.com/api/v10/webhooks/123/token?wait=1", "123"},
+		{"https://discord.com/api/webhooks/123", "123"},
+		{"https://discord.com/api/webhooks/", ""},
+		{"url", ""},
+		{"", ""},
+	}
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			assert.Equal(t, tc.want, webhookID(tc.url))
+		})
+	}
+}
+
+func TestRedactURL(t *testing.T) {
+	cases := []struct {
+		url, want string
+	}{
+		{"https://discord.com/api/webhooks/123/token", "https://discord.com/api/webhooks/123/xxxxx"},
+		{"https://discord.com/api/v10/webhooks/123/token?wait=1", "https://discord.com/api/v10/webhooks/123/xxxxx?wait=1"},
+		{"http://localhost:8080/proxy/api/webhooks/123/token", "http://localhost:8080/proxy/api/webhooks/123/xxxxx"},
+		{"https://discord.com/api/webhooks/123", "https://discord.com/api/webhooks/123"},
+		{"https://www.example.com/hook", "https://www.example.com/hook"},
+		{"https://discord.com/%zz", "xxxxx"},
+	}
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			assert.Equal(t, tc.want, redactURL(tc.url))
+		})
+	}
+}
+
+// roundTripFunc allows using a function as HTTP transport.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// unlimitedLimiter is a rate limiter which allows all requests.
+type unlimitedLimiter struct{}
+
+func (unlimitedLimiter) Wait(context.Context) error { return nil }
+func (unlimitedLimiter) Reserve() time.Duration     { return 0 }
+func (unlimitedLimiter) State() RateLimiterState    { return RateLimiterState{} }
+
+// webhookHarness allows testing how messages of several webhooks are admitted
+// by a global rate limiter, which allows one request per tick.
+type webhookHarness struct {
+	c          *Client
+	continuous map[string]bool // webhooks with continuous senders
+	done       chan struct{}
+	l          *tickLimiter
+	queued     int         // expected number of requests waiting for the global rate limit
+	sent       chan string // IDs of the webhooks of sent requests
+}
+
+func newWebhookHarness(tb testing.TB) *webhookHarness {
+	h := &webhookHarness{
+		continuous: make(map[string]bool),
+		done:       make(chan struct{}),
+		l:          &tickLimiter{ticks: make(chan struct{})},
+		sent:       make(chan string),
+	}
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		select {
+		case h.sent <- webhookID(req.URL.String()):
+		case <-h.done:
+		}
+		return &http.Response{StatusCode: http.StatusNoContent, Body: http.NoBody, Header: http.Header{}}, nil
+	})
+	h.c = NewClient(
+		WithLogger(&MyLogger{}),
+		WithHTTPClient(&http.Client{Transport: transport}),
+		WithGlobalRateLimiter(func(_ string, _ int, _ time.Duration) RateLimiter {
+			return h.l
+		}),
+		WithWebhookRateLimiter(func(_ string, _ int, _ time.Duration) RateLimiter {
+			return unlimitedLimiter{}
+		}),
+	)
+	tb.Cleanup(func() {
+		close(h.done)
+		close(h.l.ticks)
+	})
+	return h
+}
+
+// newWebhook returns a new webhook with an ID.
+func (h *webhookHarness) newWebhook(id string, options ...WebhookOption) *Webhook {
+	return h.c.NewWebhook("https://discord.com/api/webhooks/"+id+"/token", options...)
+}
+
+// startSenders starts n senders for a webhook, which continuously send messages,
+// and waits until they are all queued.
+func (h *webhookHarness) startSenders(wh *Webhook, n int) {
+	for range n {
+		go func() {
+			for {
+				select {
+				case <-h.done:
+					return
+				default:
+				}
+				wh.Execute(Message{Content: "content"}, nil)
+			}
+		}()
+	}
+	h.continuous[wh.id] = true
+	h.queued += n
+	h.waitQueued()
+}
+
+// send sends n messages with a priority concurrently to a webhook and waits until they are all queued.
+func (h *webhookHarness) send(wh *Webhook, n int, priority int) {
+	for range n {
+		go wh.Execute(Message{Content: "content"}, &WebhookExecuteOptions{Priority: priority})
+	}
+	h.queued += n
+	h.waitQueued()
+}
+
+// waitQueued waits until the expected number of requests are waiting for the global rate limit.
+func (h *webhookHarness) waitQueued() {
+	for h.c.queueGlobal.len() != h.queued {
+		runtime.Gosched()
+	}
+}
+
+// tick releases one slot of the global rate limit and returns the ID of the webhook,
+// which sent a request. It waits until the continuous senders have queued again.
+func (h *webhookHarness) tick() string {
+	h.l.ticks <- struct{}{}
+	id := <-h.sent
+	if !h.continuous[id] {
+		h.queued--
+	}
+	h.waitQueued()
+	return id
+}
+
+func TestWebhook_Fairness(t *testing.T) {
+	t.Run("should serve webhooks according to their weight", func(t *testing.T) {
+		h := newWebhookHarness(t)
+		a := h.newWebhook("a", WithWeight(5))
+		b := h.newWebhook("b")
+		h.send(a, 6, 0)
+		h.send(b, 6, 0)
+		var got []string
+		for range 12 {
+			got = append(got, h.tick())
+		}
+		assert.Equal(t, []string{"a", "a", "a", "a", "a", "b", "a", "b", "b", "b", "b", "b"}, got)
+	})
+	t.Run("should not delay a new webhook by more than one slot", func(t *testing.T) {
+		h := newWebhookHarness(t)
+		h.startSenders(h.newWebhook("chatty"), 10)
+		h.tick()
+		h.send(h.newWebhook("critical"), 1, 0)
+		var slots int
+		for h.tick() != "critical" {
+			slots++
+		}
+		assert.LessOrEqual(t, slots, 1)
+	})
+}
+
+// BenchmarkWebhook_CriticalDelay measures how many slots a high-volume webhook
+// can delay a low-volume critical webhook on the global rate limit.
+func BenchmarkWebhook_CriticalDelay(b *testing.B) {
+	h := newWebhookHarness(b)
+	h.startSenders(h.newWebhook("chatty"), 10)
+	critical := h.newWebhook("critical")
+	var maxSlots int
+	for b.Loop() {
+		h.send(critical, 1, 0)
+		var slots int
+		for h.tick() != "critical" {
+			slots++
+		}
+		maxSlots = max(maxSlots, slots)
+	}
+	b.ReportMetric(float64(maxSlots), "max-delay-slots")
+}
+
+// recordingLimiter is a rate limiter without limit, which records the times of the requests it admits.
+type recordingLimiter struct {
+	clock Clock
+
+	mu    sync.Mutex
+	times []time.Time
+}
+
+func (l *recordingLimiter) Wait(context.Context) error {
+	l.Reserve()
+	return nil
+}
+
+func (l *recordingLimiter) Reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.times = append(l.times, l.clock.Now())
+	return 0
+}
+
+func (l *recordingLimiter) State() RateLimiterState { return RateLimiterState{} }
+
+func TestWebhook_Wait(t *testing.T) {
+	t.Run("should take new global slot when webhook rate limit is exhausted in the meantime", func(t *testing.T) {
+		clock := dhooktest.NewFakeClock(time.Now())
+		global := &recordingLimiter{clock: clock}
+		var sent []time.Time
+		transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			sent = append(sent, clock.Now())
+			return &http.Response{StatusCode: http.StatusNoContent, Body: http.NoBody, Header: http.Header{}}, nil
+		})
+		c := NewClient(
+			WithClock(clock),
+			WithLogger(&MyLogger{}),
+			WithHTTPClient(&http.Client{Transport: transport}),
+			WithGlobalRateLimiter(func(_ string, _ int, _ time.Duration) RateLimiter {
+				return global
+			}),
+			WithWebhookRateLimit(1, time.Minute),
+		)
+		wh := c.NewWebhook("https://discord.com/api/webhooks/123/token")
+		start := clock.Now()
+		wh.gate.tryLock() // a previous request is being sent
+		errs := make(chan error)
+		go func() {
+			_, err := wh.Execute(Message{Content: "content"}, nil)
+			errs <- err
+		}()
+		for wh.gate.waiting() != 1 {
+			runtime.Gosched()
+		}
+		wh.limiterWebhook.Reserve() // the previous request took the webhook slot
+		wh.gate.unlock()
+		assert.NoError(t, <-errs)
+		assert.Equal(t, []time.Time{start, start.Add(time.Minute)}, global.times)
+		assert.Equal(t, []time.Time{start.Add(time.Minute)}, sent)
+	})
+}
+
+func TestWebhook_Priority(t *testing.T) {
+	t.Run("should send waiting messages of a webhook by priority", func(t *testing.T) {
+		sent := make(chan string)
+		transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			sent <- req.Header.Get("X-Priority")
+			return &http.Response{StatusCode: http.StatusNoContent, Body: http.NoBody, Header: http.Header{}}, nil
+		})
+		limiter := func(_ string, _ int, _ time.Duration) RateLimiter {
+			return unlimitedLimiter{}
+		}
+		c := NewClient(
+			WithLogger(&MyLogger{}),
+			WithHTTPClient(&http.Client{Transport: transport}),
+			WithGlobalRateLimiter(limiter),
+			WithWebhookRateLimiter(limiter),
+		)
+		wh := c.NewWebhook("https://discord.com/api/webhooks/123/token")
+		wh.gate.tryLock()
+		for i, priority := range []int{0, 1, 2} {
+			go wh.Execute(Message{Content: "content"}, &WebhookExecuteOptions{
+				Priority: priority,
+				Header:   http.Header{"X-Priority": []string{fmt.Sprint(priority)}},
+			})
+			for wh.gate.waiting() != i+1 {
+				runtime.Gosched()
+			}
+		}
+		wh.gate.unlock()
+		var got []string
+		for range 3 {
+			got = append(got, <-sent)
+		}
+		assert.Equal(t, []string{"2", "1", "0"}, got)
+	})
+}
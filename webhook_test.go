@@ -1,8 +1,15 @@
 package dhook_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
+	neturl "net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -10,6 +17,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/ErikKalkoken/go-dhook"
+	"github.com/ErikKalkoken/go-dhook/dhooktest"
 )
 
 func TestWebhook_Execute(t *testing.T) {
@@ -26,6 +34,22 @@ func TestWebhook_Execute(t *testing.T) {
 			assert.Equal(t, 1, httpmock.GetTotalCallCount())
 		}
 	})
+	t.Run("should send headers", func(t *testing.T) {
+		httpmock.Reset()
+		var got http.Header
+		httpmock.RegisterResponder("POST", url, func(req *http.Request) (*http.Response, error) {
+			got = req.Header
+			return httpmock.NewStringResponse(204, ""), nil
+		})
+		c := dhook.NewClient(dhook.WithHeader("X-Alpha", "1"), dhook.WithUserAgent("DiscordBot (https://www.example.com, 1.0)"))
+		wh := c.NewWebhook(url)
+		_, err := wh.Execute(dhook.Message{Content: "content"}, nil)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "application/json", got.Get("Content-Type"))
+			assert.Equal(t, "DiscordBot (https://www.example.com, 1.0)", got.Get("User-Agent"))
+			assert.Equal(t, "1", got.Get("X-Alpha"))
+		}
+	})
 	t.Run("should return http 400 as HTTPError", func(t *testing.T) {
 		httpmock.Reset()
 		httpmock.RegisterResponder(
@@ -39,6 +63,15 @@ func TestWebhook_Execute(t *testing.T) {
 		httpErr, _ := err.(dhook.HTTPError)
 		assert.Equal(t, 400, httpErr.Status)
 	})
+	t.Run("should return body of error response", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder("POST", url, httpmock.NewStringResponder(400, `{"code":50035}`))
+		c := dhook.NewClient()
+		wh := c.NewWebhook(url)
+		body, err := wh.Execute(dhook.Message{Content: "content"}, nil)
+		assert.ErrorAs(t, err, &dhook.HTTPError{})
+		assert.Equal(t, `{"code":50035}`, string(body))
+	})
 	t.Run("should return http 429 as TooManyRequestsError", func(t *testing.T) {
 		httpmock.Reset()
 		httpmock.RegisterResponder(
@@ -140,6 +173,195 @@ func TestWebhook_Execute(t *testing.T) {
 	})
 }
 
+func TestWebhook_ExecuteJSON(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	url := "https://www.example.com/hook"
+	t.Run("should send body as is", func(t *testing.T) {
+		httpmock.Reset()
+		var got []byte
+		httpmock.RegisterResponder("POST", url, func(req *http.Request) (*http.Response, error) {
+			got, _ = io.ReadAll(req.Body)
+			return httpmock.NewStringResponse(204, ""), nil
+		})
+		c := dhook.NewClient(dhook.WithValidationPolicy(dhook.ValidationStrict))
+		wh := c.NewWebhook(url)
+		body := `{"flags":4096,"components":[{"type":1}]}`
+		_, err := wh.ExecuteJSON([]byte(body), nil)
+		if assert.NoError(t, err) {
+			assert.Equal(t, body, string(got))
+		}
+	})
+	t.Run("should send valid JSON, which does not decode into a message", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder("POST", url, httpmock.NewStringResponder(204, ""))
+		c := dhook.NewClient()
+		wh := c.NewWebhook(url)
+		_, err := wh.ExecuteJSON([]byte(`{"embeds":[{"timestamp":"2024-01-01T00:00:00"}]}`), nil)
+		if assert.NoError(t, err) {
+			assert.Equal(t, 1, httpmock.GetTotalCallCount())
+		}
+	})
+	t.Run("should reject invalid JSON", func(t *testing.T) {
+		httpmock.Reset()
+		c := dhook.NewClient()
+		wh := c.NewWebhook(url)
+		_, err := wh.ExecuteJSON([]byte("{"), nil)
+		assert.ErrorIs(t, err, dhook.ErrInvalidMessage)
+		assert.Equal(t, 0, httpmock.GetTotalCallCount())
+	})
+}
+
+func TestWebhook_TryExecute(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	url := "https://www.example.com/hook"
+	t.Run("can post a message when rate limits allow it", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder("POST", url, httpmock.NewStringResponder(204, ""))
+		c := dhook.NewClient()
+		wh := c.NewWebhook(url)
+		_, err := wh.TryExecute(dhook.Message{Content: "content"}, nil)
+		if assert.NoError(t, err) {
+			assert.Equal(t, 1, httpmock.GetTotalCallCount())
+		}
+	})
+	t.Run("should return error when webhook rate limit would block", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder("POST", url, httpmock.NewStringResponder(204, ""))
+		c := dhook.NewClient(dhook.WithWebhookRateLimit(1, time.Minute))
+		wh := c.NewWebhook(url)
+		_, err := wh.TryExecute(dhook.Message{Content: "content"}, nil)
+		if !assert.NoError(t, err) {
+			t.Fatal()
+		}
+		_, err = wh.TryExecute(dhook.Message{Content: "content"}, nil)
+		assert.ErrorIs(t, err, dhook.ErrWouldBlock)
+		var errWouldBlock dhook.WouldBlockError
+		if assert.ErrorAs(t, err, &errWouldBlock) {
+			assert.InDelta(t, time.Minute, errWouldBlock.RetryAfter, float64(time.Second))
+		}
+		assert.Equal(t, 1, httpmock.GetTotalCallCount())
+	})
+	t.Run("should return error when global rate limit would block", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder("POST", url, httpmock.NewStringResponder(204, ""))
+		c := dhook.NewClient(dhook.WithGlobalRateLimit(1, time.Minute))
+		wh1 := c.NewWebhook(url)
+		wh2 := c.NewWebhook(url)
+		_, err := wh1.TryExecute(dhook.Message{Content: "content"}, nil)
+		if !assert.NoError(t, err) {
+			t.Fatal()
+		}
+		_, err = wh2.TryExecute(dhook.Message{Content: "content"}, nil)
+		assert.ErrorIs(t, err, dhook.ErrWouldBlock)
+		assert.Equal(t, 1, httpmock.GetTotalCallCount())
+	})
+}
+
+func TestWebhook_EstimateDelay(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	url := "https://www.example.com/hook"
+	httpmock.RegisterResponder("POST", url, httpmock.NewStringResponder(204, ""))
+	t.Run("should report no delay when rate limits are free", func(t *testing.T) {
+		c := dhook.NewClient()
+		wh := c.NewWebhook(url)
+		assert.Zero(t, wh.EstimateDelay())
+		assert.Zero(t, c.EstimateDelay())
+	})
+	t.Run("should report delay of exhausted webhook rate limit", func(t *testing.T) {
+		c := dhook.NewClient(dhook.WithWebhookRateLimit(1, time.Minute))
+		wh := c.NewWebhook(url)
+		_, err := wh.Execute(dhook.Message{Content: "content"}, nil)
+		if assert.NoError(t, err) {
+			assert.InDelta(t, time.Minute, wh.EstimateDelay(), float64(time.Second))
+			assert.Zero(t, c.EstimateDelay())
+		}
+	})
+	t.Run("should report delay of exhausted global rate limit", func(t *testing.T) {
+		c := dhook.NewClient(dhook.WithGlobalRateLimit(1, time.Minute))
+		wh := c.NewWebhook(url)
+		_, err := wh.Execute(dhook.Message{Content: "content"}, nil)
+		if assert.NoError(t, err) {
+			assert.InDelta(t, time.Minute, wh.EstimateDelay(), float64(time.Second))
+			assert.InDelta(t, time.Minute, c.EstimateDelay(), float64(time.Second))
+		}
+	})
+}
+
+func TestWebhook_RateLimit(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	url := "https://www.example.com/hook"
+	t.Run("should report configured rate limit", func(t *testing.T) {
+		c := dhook.NewClient(dhook.WithWebhookRateLimit(20, time.Minute))
+		wh := c.NewWebhook(url)
+		requests, period := wh.RateLimit()
+		assert.Equal(t, 20, requests)
+		assert.Equal(t, time.Minute, period)
+	})
+	t.Run("should lower learned rate limit after 429", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder("POST", url, httpmock.NewStringResponder(429, "").HeaderSet(http.Header{"Retry-After": []string{"1"}}))
+		c := dhook.NewClient(dhook.WithAdaptiveWebhookRateLimit(1, 30))
+		wh := c.NewWebhook(url)
+		_, err := wh.Execute(dhook.Message{Content: "content"}, nil)
+		assert.ErrorAs(t, err, &dhook.TooManyRequestsError{})
+		requests, period := wh.RateLimit()
+		assert.Equal(t, 22, requests)
+		assert.Equal(t, 60*time.Second, period)
+	})
+	t.Run("should not lower learned rate limit after global 429", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder("POST", url, httpmock.NewJsonResponderOrPanic(429, map[string]any{"global": true}))
+		c := dhook.NewClient(dhook.WithAdaptiveWebhookRateLimit(1, 30))
+		wh := c.NewWebhook(url)
+		_, err := wh.Execute(dhook.Message{Content: "content"}, nil)
+		assert.ErrorAs(t, err, &dhook.TooManyRequestsError{})
+		requests, _ := wh.RateLimit()
+		assert.Equal(t, 30, requests)
+	})
+}
+
+func TestWebhook_Clock(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	url := "https://www.example.com/hook"
+	httpmock.RegisterResponder("POST", url, httpmock.NewStringResponder(204, ""))
+	t.Run("should wait for webhook rate limit with custom clock", func(t *testing.T) {
+		clock := dhooktest.NewFakeClock(time.Now())
+		c := dhook.NewClient(dhook.WithClock(clock), dhook.WithWebhookRateLimit(2, time.Minute))
+		wh := c.NewWebhook(url)
+		start := clock.Now()
+		for range 3 {
+			_, err := wh.Execute(dhook.Message{Content: "content"}, nil)
+			if !assert.NoError(t, err) {
+				t.Fatal()
+			}
+		}
+		assert.Equal(t, start.Add(time.Minute), clock.Now())
+		assert.Equal(t, []time.Duration{time.Minute}, clock.Sleeps())
+	})
+	t.Run("should expire rate limits with custom clock", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder("POST", url, httpmock.NewStringResponder(429, "").HeaderSet(http.Header{"Retry-After": []string{"30"}}))
+		clock := dhooktest.NewFakeClock(time.Now())
+		c := dhook.NewClient(dhook.WithClock(clock))
+		wh := c.NewWebhook(url)
+		wh.Execute(dhook.Message{Content: "content"}, nil)
+		assert.Equal(t, 30*time.Second, wh.EstimateDelay())
+		clock.Advance(31 * time.Second)
+		assert.Zero(t, wh.EstimateDelay())
+	})
+}
+
+func TestWouldBlockError(t *testing.T) {
+	err := dhook.WouldBlockError{RetryAfter: 3 * time.Second}
+	assert.Equal(t, "would block for 3s", err.Error())
+	assert.ErrorIs(t, err, dhook.ErrWouldBlock)
+}
+
 func TestTooManyRequestsError_Error(t *testing.T) {
 	t.Run("return normal error text", func(t *testing.T) {
 		err := dhook.TooManyRequestsError{}
@@ -157,3 +379,232 @@ func TestHTTPError_Error(t *testing.T) {
 	}
 	assert.Equal(t, "message", err.Error())
 }
+
+func TestWithWeight(t *testing.T) {
+	assert.Panics(t, func() {
+		dhook.WithWeight(0)
+	})
+	assert.Panics(t, func() {
+		dhook.WithWeight(-1)
+	})
+}
+
+func TestWebhook_Redaction(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	const token = "secret-token"
+	url := "https://discord.com/api/webhooks/123/" + token
+	newClient := func(buf *bytes.Buffer, opts ...dhook.ClientOption) *dhook.Client {
+		logger := slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		return dhook.NewClient(append(opts, dhook.WithLogger(logger))...)
+	}
+	t.Run("should redact token in logs", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder("POST", url, httpmock.NewStringResponder(200, `{"id":"1"}`))
+		var buf bytes.Buffer
+		c := newClient(&buf)
+		wh := c.NewWebhook(url)
+		_, err := wh.Execute(dhook.Message{Content: "content"}, &dhook.WebhookExecuteOptions{Wait: true})
+		if assert.NoError(t, err) {
+			assert.NotContains(t, buf.String(), token)
+			assert.Contains(t, buf.String(), "https://discord.com/api/webhooks/123/xxxxx")
+			assert.Contains(t, buf.String(), "content")
+		}
+	})
+	t.Run("should redact token in errors", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder("POST", url, httpmock.NewErrorResponder(assert.AnError))
+		var buf bytes.Buffer
+		c := newClient(&buf)
+		wh := c.NewWebhook(url)
+		_, err := wh.Execute(dhook.Message{Content: "content"}, nil)
+		if assert.Error(t, err) {
+			assert.NotContains(t, err.Error(), token)
+			assert.ErrorIs(t, err, assert.AnError)
+		}
+	})
+	t.Run("should redact token when formatting", func(t *testing.T) {
+		c := dhook.NewClient()
+		wh := c.NewWebhook(url)
+		for _, s := range []string{fmt.Sprint(wh), fmt.Sprintf("%+v", wh), fmt.Sprintf("%#v", wh)} {
+			assert.NotContains(t, s, token)
+			assert.Contains(t, s, "webhooks/123/xxxxx")
+		}
+	})
+	t.Run("can redact bodies in logs", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder("POST", url, httpmock.NewStringResponder(200, `{"content":"alpha"}`))
+		var buf bytes.Buffer
+		c := newClient(&buf, dhook.WithRedactedBodies())
+		wh := c.NewWebhook(url)
+		_, err := wh.Execute(dhook.Message{Content: "bravo"}, &dhook.WebhookExecuteOptions{Wait: true})
+		if assert.NoError(t, err) {
+			assert.NotContains(t, buf.String(), "alpha")
+			assert.NotContains(t, buf.String(), "bravo")
+		}
+	})
+}
+
+func TestWebhook_ExecuteOptions(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	url := "https://www.example.com/hook"
+	t.Run("should apply timeout", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder("POST", url, func(req *http.Request) (*http.Response, error) {
+			<-req.Context().Done()
+			return nil, req.Context().Err()
+		})
+		c := dhook.NewClient()
+		wh := c.NewWebhook(url)
+		_, err := wh.Execute(dhook.Message{Content: "content"}, &dhook.WebhookExecuteOptions{Timeout: 10 * time.Millisecond})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+	t.Run("should fail when rate limit wait would exceed maximum", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder("POST", url, httpmock.NewStringResponder(204, ""))
+		clock := dhooktest.NewFakeClock(time.Now())
+		c := dhook.NewClient(dhook.WithClock(clock), dhook.WithWebhookRateLimit(1, time.Minute))
+		wh := c.NewWebhook(url)
+		_, err := wh.Execute(dhook.Message{Content: "content"}, nil)
+		assert.NoError(t, err)
+		opt := &dhook.WebhookExecuteOptions{MaxRateLimitWait: 10 * time.Second}
+		_, err = wh.Execute(dhook.Message{Content: "content"}, opt)
+		assert.ErrorIs(t, err, dhook.ErrWouldBlock)
+		assert.Equal(t, 1, httpmock.GetTotalCallCount())
+		opt = &dhook.WebhookExecuteOptions{MaxRateLimitWait: 2 * time.Minute}
+		_, err = wh.Execute(dhook.Message{Content: "content"}, opt)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, httpmock.GetTotalCallCount())
+	})
+	t.Run("should pass correlation ID to hooks and logs", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder("POST", url, httpmock.NewStringResponder(204, ""))
+		var buf bytes.Buffer
+		var got string
+		c := dhook.NewClient(
+			dhook.WithLogger(slog.New(slog.NewTextHandler(&buf, nil))),
+			dhook.WithHooks(dhook.Hooks{
+				BeforeRequest: func(req *http.Request, _ dhook.Message) {
+					got = dhook.CorrelationID(req.Context())
+				},
+			}),
+		)
+		wh := c.NewWebhook(url)
+		_, err := wh.Execute(dhook.Message{Content: "content"}, &dhook.WebhookExecuteOptions{CorrelationID: "abc"})
+		if assert.NoError(t, err) {
+			assert.Equal(t, "abc", got)
+			assert.Contains(t, buf.String(), "correlationID=abc")
+		}
+	})
+	t.Run("should send additional headers", func(t *testing.T) {
+		httpmock.Reset()
+		var got string
+		httpmock.RegisterResponder("POST", url, func(req *http.Request) (*http.Response, error) {
+			got = req.Header.Get("X-Alpha")
+			return httpmock.NewStringResponse(204, ""), nil
+		})
+		c := dhook.NewClient()
+		wh := c.NewWebhook(url)
+		opt := &dhook.WebhookExecuteOptions{Header: http.Header{"X-Alpha": {"1"}}}
+		_, err := wh.Execute(dhook.Message{Content: "content"}, opt)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "1", got)
+		}
+	})
+	t.Run("should override user agent with additional headers", func(t *testing.T) {
+		httpmock.Reset()
+		var got string
+		httpmock.RegisterResponder("POST", url, func(req *http.Request) (*http.Response, error) {
+			got = req.Header.Get("User-Agent")
+			return httpmock.NewStringResponse(204, ""), nil
+		})
+		c := dhook.NewClient()
+		wh := c.NewWebhook(url)
+		opt := &dhook.WebhookExecuteOptions{Header: http.Header{"User-Agent": {"DiscordBot (https://www.example.com, 1.0)"}}}
+		_, err := wh.Execute(dhook.Message{Content: "content"}, opt)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "DiscordBot (https://www.example.com, 1.0)", got)
+		}
+	})
+	t.Run("should send additional query parameters", func(t *testing.T) {
+		httpmock.Reset()
+		var got string
+		httpmock.RegisterResponder("POST", url, func(req *http.Request) (*http.Response, error) {
+			got = req.URL.RawQuery
+			return httpmock.NewStringResponse(200, "{}"), nil
+		})
+		c := dhook.NewClient()
+		wh := c.NewWebhook(url)
+		opt := &dhook.WebhookExecuteOptions{Query: neturl.Values{"thread_id": {"42"}}, Wait: true}
+		_, err := wh.Execute(dhook.Message{Content: "content"}, opt)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "thread_id=42&wait=1", got)
+		}
+	})
+	t.Run("should reject negative durations", func(t *testing.T) {
+		c := dhook.NewClient()
+		wh := c.NewWebhook(url)
+		_, err := wh.Execute(dhook.Message{Content: "content"}, &dhook.WebhookExecuteOptions{Timeout: -1})
+		assert.ErrorIs(t, err, dhook.ErrInvalidConfiguration)
+		_, err = wh.Execute(dhook.Message{Content: "content"}, &dhook.WebhookExecuteOptions{MaxRateLimitWait: -1})
+		assert.ErrorIs(t, err, dhook.ErrInvalidConfiguration)
+	})
+}
+
+func TestWebhook_ValidationPolicy(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	url := "https://www.example.com/hook"
+	httpmock.RegisterResponder("POST", url, httpmock.NewStringResponder(204, ""))
+	m := dhook.Message{Content: strings.Repeat("x", 2001)}
+	cases := []struct {
+		name      string
+		policy    dhook.ValidationPolicy
+		wantErr   bool
+		wantCalls int
+	}{
+		{"off", dhook.ValidationOff, false, 1},
+		{"strict", dhook.ValidationStrict, true, 0},
+		{"fix", dhook.ValidationFix, false, 1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			httpmock.ZeroCallCounters()
+			c := dhook.NewClient(dhook.WithValidationPolicy(tc.policy))
+			wh := c.NewWebhook(url)
+			_, err := wh.Execute(m, nil)
+			if tc.wantErr {
+				assert.ErrorIs(t, err, dhook.ErrInvalidMessage)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.wantCalls, httpmock.GetTotalCallCount())
+		})
+	}
+	t.Run("should send fixed message", func(t *testing.T) {
+		httpmock.Reset()
+		var got dhook.Message
+		httpmock.RegisterResponder("POST", url, func(req *http.Request) (*http.Response, error) {
+			if err := json.NewDecoder(req.Body).Decode(&got); err != nil {
+				return nil, err
+			}
+			return httpmock.NewStringResponse(204, ""), nil
+		})
+		c := dhook.NewClient(dhook.WithValidationPolicy(dhook.ValidationFix))
+		wh := c.NewWebhook(url)
+		_, err := wh.Execute(m, nil)
+		if assert.NoError(t, err) {
+			assert.NoError(t, got.Validate())
+		}
+	})
+	t.Run("should count characters as configured", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder("POST", url, httpmock.NewStringResponder(204, ""))
+		c := dhook.NewClient(dhook.WithValidationPolicy(dhook.ValidationStrict), dhook.WithCounting(dhook.CountUTF16))
+		wh := c.NewWebhook(url)
+		_, err := wh.Execute(dhook.Message{Content: strings.Repeat("😀", 1001)}, nil)
+		assert.ErrorIs(t, err, dhook.ErrInvalidMessage)
+		assert.Equal(t, 0, httpmock.GetTotalCallCount())
+	})
+}